@@ -88,3 +88,16 @@ func Build(params []string, reader io.Reader) ([]string, error) {
 
 	return []string{result}, nil
 }
+
+// Patterns splits comma separated patterns skipping empty ones.
+func Patterns(value string) []string {
+	var patterns []string
+
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+
+	return patterns
+}
@@ -134,3 +134,21 @@ func FuzzBuildText(f *testing.F) {
 		}
 	})
 }
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{},
+		{value: " , ,"},
+		{value: "errors.*", expected: []string{"errors.*"}},
+		{value: " a.b , c ,,", expected: []string{"a.b", "c"}},
+	}
+
+	for i, tc := range tests {
+		if result := Patterns(tc.value); slices.Compare(result, tc.expected) != 0 {
+			t.Errorf("case %d: expected %#v, got %#v", i, tc.expected, result)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package bundle implements translation of JSON and YAML locale files (resource bundles).
+// Only string leaf values are translated, keys, nesting, formatting and other values are kept as is.
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// Options are parameters of a bundle translation.
+type Options struct {
+	Source  string   // source language, a single root key with this name is renamed to Target one
+	Target  string   // target language
+	Include []string // key path patterns of translated values, all values if empty
+	Exclude []string // key path patterns of skipped values
+
+	// Incremental translation: if Manifest is not nil, only new or changed source values are translated,
+	// other ones are taken from Previous output data. Manifest is updated after translation.
+	Previous []byte
+	Manifest Manifest
+}
+
+// leaf is a string value (or a key) of a bundle and its position in the source data.
+type leaf struct {
+	path   string
+	text   string
+	start  int
+	end    int
+	encode func(string) string
+}
+
+// document is a parsed bundle.
+type document struct {
+	leaves []*leaf
+	root   *leaf // a single root key
+}
+
+// parser parses bundle data.
+type parser func(data string) (*document, error)
+
+// matchSegments returns true if path segments start with pattern segments.
+// Every pattern segment is a shell pattern, "**" matches any number of segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// Match returns true if a dot separated key path matches the pattern or it is nested into matched one.
+// Example: pattern "errors.*" matches "errors.not_found" and "errors.auth.denied".
+func Match(pattern, keyPath string) bool {
+	return matchSegments(strings.Split(pattern, "."), strings.Split(keyPath, "."))
+}
+
+// Allowed returns true if the key path should be translated according to include and exclude patterns.
+func (o *Options) Allowed(keyPath string) bool {
+	match := func(pattern string) bool {
+		return Match(pattern, keyPath)
+	}
+
+	if len(o.Include) > 0 && !slices.ContainsFunc(o.Include, match) {
+		return false
+	}
+
+	return !slices.ContainsFunc(o.Exclude, match)
+}
+
+// parserByExtension returns a parser for the file extension.
+func parserByExtension(ext string) (parser, error) {
+	switch strings.ToLower(ext) {
+	case ".json":
+		return parseJSON, nil
+	case ".yaml", ".yml":
+		return parseYAML, nil
+	default:
+		return nil, fmt.Errorf("unsupported bundle format %q", ext)
+	}
+}
+
+// replace returns data with replaced leaf values.
+func replace(data string, leaves []*leaf, values []string) string {
+	var (
+		b     strings.Builder
+		start int
+	)
+
+	for i, l := range leaves {
+		b.WriteString(data[start:l.start])
+		b.WriteString(l.encode(values[i]))
+		start = l.end
+	}
+
+	b.WriteString(data[start:])
+	return b.String()
+}
+
+// Translate translates string values of JSON or YAML bundle data, format is defined by extension ext.
+func Translate(ctx context.Context, data []byte, ext string, options *Options, fn translation.BatchFunc) ([]byte, error) {
+	parse, err := parserByExtension(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	source := string(data)
+	doc, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	previous, err := previousValues(options.Previous, parse, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		leaves   []*leaf
+		values   []string
+		pending  []int // indexes of values to translate
+		texts    []string
+		manifest = Manifest{}
+	)
+
+	for _, l := range doc.leaves {
+		if strings.TrimSpace(l.text) == "" || !options.Allowed(l.path) {
+			continue
+		}
+
+		leaves = append(leaves, l)
+		if options.Manifest != nil {
+			if value, ok := options.Manifest.reuse(l, previous); ok {
+				values = append(values, value)
+				if hashes, found := options.Manifest[l.path]; found {
+					manifest[l.path] = hashes
+				}
+				continue
+			}
+		}
+
+		pending = append(pending, len(values))
+		values = append(values, "")
+		texts = append(texts, l.text)
+	}
+
+	if len(texts) > 0 {
+		translations, err := fn(ctx, texts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(translations) != len(texts) {
+			return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(texts))
+		}
+
+		for i, j := range pending {
+			values[j] = translations[i]
+			manifest[leaves[j].path] = Hashes{Source: Hash(texts[i]), Target: Hash(translations[i])}
+		}
+	}
+
+	if options.Manifest != nil {
+		clear(options.Manifest)
+		maps.Copy(options.Manifest, manifest)
+	}
+
+	if r := doc.root; r != nil && r.text == options.Source && options.Target != "" {
+		leaves = append([]*leaf{r}, leaves...)
+		values = append([]string{options.Target}, values...)
+	}
+
+	return []byte(replace(source, leaves, values)), nil
+}
@@ -0,0 +1,256 @@
+package bundle
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/internal/testfn"
+)
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "errors", path: "errors", expected: true},
+		{pattern: "errors", path: "errors.auth.denied", expected: true},
+		{pattern: "errors", path: "messages.errors"},
+		{pattern: "errors.*", path: "errors.not_found", expected: true},
+		{pattern: "*.title", path: "page.title", expected: true},
+		{pattern: "*.title", path: "page.menu.title"},
+		{pattern: "**.title", path: "page.menu.title", expected: true},
+		{pattern: "**.title", path: "title", expected: true},
+		{pattern: "menu.*.label", path: "menu.0.label", expected: true},
+		{pattern: "menu.[", path: "menu.x"},
+	}
+
+	for i, tc := range testCases {
+		if result := Match(tc.pattern, tc.path); result != tc.expected {
+			t.Errorf("case %d: expected %v for %q and %q", i, tc.expected, tc.pattern, tc.path)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ext      string
+		source   string
+		options  Options
+		expected string
+		err      string
+	}{
+		{
+			name: "json",
+			ext:  ".json",
+			source: `{
+  "en": {
+    "title": "Hello, <b>world</b>",
+    "count": 10,
+    "flag": true,
+    "empty": "",
+    "menu": [{"label": "open"}, {"label": "close \"all\""}],
+    "errors": {"auth": "denied", "code": null}
+  }
+}
+`,
+			options: Options{Source: "en", Target: "ru", Exclude: []string{"**.errors"}},
+			expected: `{
+  "ru": {
+    "title": "HELLO, <B>WORLD</B>",
+    "count": 10,
+    "flag": true,
+    "empty": "",
+    "menu": [{"label": "OPEN"}, {"label": "CLOSE \"ALL\""}],
+    "errors": {"auth": "denied", "code": null}
+  }
+}
+`,
+		},
+		{
+			name:     "json_include",
+			ext:      ".JSON",
+			source:   `{"a": "x", "b": {"c": "y", "d": ["z"]}, "e": "w"}`,
+			options:  Options{Source: "a", Target: "b", Include: []string{"b.d"}},
+			expected: `{"a": "x", "b": {"c": "y", "d": ["Z"]}, "e": "w"}`,
+		},
+		{
+			name: "yaml",
+			ext:  ".yml",
+			source: `# comment
+en:
+  title: Hello world # trailing comment
+  quoted: "Say \"hi\""
+  single: 'it''s'
+  number: 42
+  enabled: yes
+  alias: *anchor
+  list:
+    - first
+    - name: second
+      value: 1
+  nested:
+    deep: text: with colon
+  literal: |
+    line one
+    line two
+
+  folded: >
+    folded
+    text
+`,
+			options: Options{Source: "en", Target: "de"},
+			expected: `# comment
+de:
+  title: HELLO WORLD # trailing comment
+  quoted: "SAY \"HI\""
+  single: 'IT''S'
+  number: 42
+  enabled: yes
+  alias: *anchor
+  list:
+    - FIRST
+    - name: SECOND
+      value: 1
+  nested:
+    deep: "TEXT: WITH COLON"
+  literal: |
+    LINE ONE
+    LINE TWO
+
+  folded: >
+    FOLDED TEXT
+`,
+		},
+		{
+			name:     "yaml_compact_sequence",
+			ext:      ".yaml",
+			source:   "items:\n- a\n- b\nother: c\n",
+			options:  Options{Source: "en", Target: "de", Exclude: []string{"items.1"}},
+			expected: "items:\n- A\n- b\nother: C\n",
+		},
+		{
+			name:     "yaml_escapes",
+			ext:      ".yaml",
+			source:   `a: "tab\there \e[1m \N \_ \x41\u00e9 \U0001F600 \/ \ end"` + "\n",
+			options:  Options{Source: "en", Target: "de"},
+			expected: `a: "TAB\tHERE \e[1M \N \_ AÉ 😀 /  END"` + "\n",
+		},
+		{
+			name:     "yaml_crlf",
+			ext:      ".yaml",
+			source:   "en:\r\n  literal: |\r\n    one\r\n    two\r\n  folded: >\r\n    a\r\n    b\r\n\r\n    c\r\n  other: x\r\n",
+			options:  Options{Source: "en", Target: "de"},
+			expected: "de:\r\n  literal: |\r\n    ONE\r\n    TWO\r\n  folded: >\r\n    A B\r\n\r\n    C\r\n  other: X\r\n",
+		},
+		{
+			name:   "yaml_bad_escape",
+			ext:    ".yaml",
+			source: `a: "\q"`,
+			err:    `failed to parse bundle: line 1: invalid double-quoted scalar of key "a": unknown escape sequence "\\q"`,
+		},
+		{name: "unknown", ext: ".xml", err: `unsupported bundle format ".xml"`},
+		{name: "bad_json", ext: ".json", source: `{"a": }`, err: "failed to parse bundle: "},
+		{name: "json_string", ext: ".json", source: `"a"`, err: "failed to parse bundle: root value is not an object or array"},
+		{
+			name:     "yaml_multiline_plain",
+			ext:      ".yaml",
+			source:   "a: first\n  second\n\n  third # note\nb:\n  - x\n    y\n  - |\n    z\n",
+			options:  Options{Source: "en", Target: "de"},
+			expected: "a: \"FIRST SECOND\\nTHIRD\" # note\nb:\n  - X Y\n  - |\n    Z\n",
+		},
+		{
+			name:     "yaml_multiline_quoted",
+			ext:      ".yaml",
+			source:   "a: \"one\n  two \\\n  three\" # note\nb: 'it''s\r\n\r\n  long'\r\nc: x\n",
+			options:  Options{Source: "en", Target: "de"},
+			expected: "a: \"ONE TWO THREE\" # note\nb: \"IT'S\\nLONG\"\r\nc: X\n",
+		},
+		{
+			name:     "yaml_multiline_flow",
+			ext:      ".yaml",
+			source:   "a:\n  b: [x,\n    y: z]\n  c: d\n",
+			options:  Options{Source: "en", Target: "de"},
+			expected: "a:\n  b: [x,\n    y: z]\n  c: D\n",
+		},
+		{
+			name:   "yaml_not_closed",
+			ext:    ".yaml",
+			source: "a: 'x\n  y\n",
+			err:    `failed to parse bundle: line 1: not closed quoted scalar of key "a"`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Translate(context.Background(), []byte(tc.source), tc.ext, &tc.options, testfn.Upper)
+			if err != nil {
+				if e := err.Error(); tc.err == "" || !strings.HasPrefix(e, tc.err) {
+					t.Errorf("expected error %q, got %q", tc.err, e)
+				}
+				return
+			}
+
+			if tc.err != "" {
+				t.Errorf("expected error %q", tc.err)
+			}
+
+			if r := string(result); r != tc.expected {
+				t.Errorf("unexpected result:\n%s", r)
+			}
+		})
+	}
+}
+
+func TestTranslateIncremental(t *testing.T) {
+	var requested []string
+	fn := func(ctx context.Context, texts []string) ([]string, error) {
+		requested = append(requested, texts...)
+		return testfn.Upper(ctx, texts)
+	}
+
+	options := &Options{Source: "en", Target: "ru", Manifest: Manifest{}}
+	first := `{"en": {"a": "one", "b": "two", "c": "three", "e": "six"}}`
+
+	result, err := Translate(context.Background(), []byte(first), ".json", options, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"ru": {"a": "ONE", "b": "TWO", "c": "THREE", "e": "SIX"}}`; string(result) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(result))
+	}
+
+	if n := len(options.Manifest); n != 4 {
+		t.Fatalf("expected 4 manifest entries, got %d", n)
+	}
+
+	// "b" is edited by hand, "c" and "b" sources are changed, "d" is new, "e" is removed
+	requested = nil
+	options.Previous = []byte(`{"ru": {"a": "ONE", "b": "Two!", "c": "THREE", "e": "SIX"}}`)
+	second := `{"en": {"a": "one", "b": "two 2", "c": "four", "d": "five"}}`
+
+	result, err = Translate(context.Background(), []byte(second), ".json", options, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"ru": {"a": "ONE", "b": "Two!", "c": "FOUR", "d": "FIVE"}}`; string(result) != expected {
+		t.Errorf("expected %q, got %q", expected, string(result))
+	}
+
+	if expected := "four,five"; strings.Join(requested, ",") != expected {
+		t.Errorf("expected requested %q, got %q", expected, strings.Join(requested, ","))
+	}
+
+	if _, ok := options.Manifest["en.e"]; ok || len(options.Manifest) != 4 {
+		t.Errorf("unexpected manifest %v", options.Manifest)
+	}
+
+	if h := options.Manifest["en.b"]; h.Target != Hash("TWO") {
+		t.Error("hand-edited value manifest entry is changed")
+	}
+}
@@ -0,0 +1,129 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonFrame is a JSON object or array which is being parsed.
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+	index     int
+	keys      int
+}
+
+// segment returns a path segment of the current frame's value.
+func (f *jsonFrame) segment() string {
+	if f.object {
+		return f.key
+	}
+	return strconv.Itoa(f.index)
+}
+
+// next moves the frame to the next value.
+func (f *jsonFrame) next() {
+	if f.object {
+		f.expectKey = true
+	} else {
+		f.index++
+	}
+}
+
+// encodeJSON encodes a string as JSON string without HTML escaping.
+func encodeJSON(s string) string {
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(s); err != nil {
+		return strconv.Quote(s) // unreachable for strings
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// parseJSON parses JSON bundle data keeping positions of string values.
+func parseJSON(data string) (*document, error) {
+	var (
+		doc   = &document{}
+		dec   = json.NewDecoder(strings.NewReader(data))
+		stack []*jsonFrame
+		prev  int
+	)
+	dec.UseNumber()
+
+	path := func() string {
+		segments := make([]string, len(stack))
+		for i, f := range stack {
+			segments[i] = f.segment()
+		}
+		return strings.Join(segments, ".")
+	}
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		end := int(dec.InputOffset())
+		start := prev
+		prev = end
+
+		var top *jsonFrame
+		if n := len(stack); n > 0 {
+			top = stack[n-1]
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				stack = append(stack, &jsonFrame{object: t == '{', expectKey: t == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+				if n := len(stack); n > 0 {
+					stack[n-1].next()
+				}
+			}
+			continue
+		case string:
+			start += strings.IndexByte(data[start:end], '"')
+			item := &leaf{text: t, start: start, end: end, encode: encodeJSON}
+
+			if top != nil && top.expectKey {
+				top.key, top.expectKey = t, false
+				top.keys++
+
+				if len(stack) == 1 {
+					doc.root = nil
+					if top.keys == 1 {
+						doc.root = item
+					}
+				}
+				continue
+			}
+
+			item.path = path()
+			doc.leaves = append(doc.leaves, item)
+		}
+
+		if top == nil {
+			return nil, fmt.Errorf("root value is not an object or array")
+		}
+		top.next()
+	}
+
+	return doc, nil
+}
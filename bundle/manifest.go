@@ -0,0 +1,127 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestSuffix is a suffix of manifest file name, the manifest is stored next to the output file.
+const ManifestSuffix = ".manifest.json"
+
+// Hashes are hashes of a source value and its machine translation written to the output.
+type Hashes struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Manifest is a map of key paths to hashes of translated values.
+// It allows to translate only new or changed source values and detect hand-edited translations.
+type Manifest map[string]Hashes
+
+// Hash returns a hex-encoded SHA-256 hash of the text.
+func Hash(text string) string {
+	h := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(h[:])
+}
+
+// ManifestName returns a manifest file name for the output file.
+func ManifestName(output string) string {
+	return output + ManifestSuffix
+}
+
+// LoadManifest reads a manifest from the file, it returns empty manifest if the file doesn't exist.
+func LoadManifest(fileName string) (Manifest, error) {
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Manifest{}, nil
+		}
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	m := Manifest{}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest %q: %w", fileName, err)
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest to the file.
+func (m Manifest) Save(fileName string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Clean(fileName), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// previousValues returns values of the previous output by key paths of the source document.
+// Paths of the output with renamed root key are converted back to source ones.
+func previousValues(data []byte, parse parser, options *Options) (map[string]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	doc, err := parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse previous output: %w", err)
+	}
+
+	renamed := doc.root != nil && options.Target != "" && options.Source != options.Target &&
+		doc.root.text == options.Target
+
+	values := make(map[string]string, len(doc.leaves))
+	for _, l := range doc.leaves {
+		keyPath := l.path
+		if renamed {
+			if rest, ok := cutRoot(keyPath, options.Target); ok {
+				keyPath = options.Source + rest
+			}
+		}
+		values[keyPath] = l.text
+	}
+
+	return values, nil
+}
+
+// cutRoot returns a rest of key path after the root segment.
+func cutRoot(keyPath, root string) (string, bool) {
+	switch {
+	case keyPath == root:
+		return "", true
+	case strings.HasPrefix(keyPath, root+"."):
+		return keyPath[len(root):], true
+	default:
+		return "", false
+	}
+}
+
+// reuse returns a value of the previous output which should be kept for the source leaf.
+// Unchanged sources keep their translations, hand-edited translations (which hashes differ from
+// the manifest ones) or translations without manifest entries are never overwritten.
+func (m Manifest) reuse(l *leaf, previous map[string]string) (string, bool) {
+	value, ok := previous[l.path]
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", false
+	}
+
+	hashes, ok := m[l.path]
+	if !ok {
+		return value, true // unknown translation, it was added by hand
+	}
+
+	return value, hashes.Source == Hash(l.text) || hashes.Target != Hash(value)
+}
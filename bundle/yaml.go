@@ -0,0 +1,583 @@
+package bundle
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// YAML locale files are parsed line by line, so only a common subset of YAML is supported:
+// block mappings and sequences, plain and quoted scalars, literal and folded block scalars.
+// Line breaks of multi-line plain and quoted scalars are folded, their translations are written to one line.
+// Comments, anchors, aliases, tags and flow collections are kept as is.
+
+var (
+	yamlKeyRegexp     = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"\-?:,\[\]{}&*!|>%@` + "`" + `][^#]*?|-[^\s#][^#]*?)\s*:(?:\s+|$)`)
+	yamlDashRegexp    = regexp.MustCompile(`^-(?:\s+|$)`)
+	yamlBlockRegexp   = regexp.MustCompile(`^[|>][+-]?[1-9]?\s*(?:#.*)?$`)
+	yamlSpecialRegexp = regexp.MustCompile(`^(?:~|null|Null|NULL|true|True|TRUE|false|False|FALSE|yes|Yes|YES|no|No|NO|on|On|ON|off|Off|OFF|[-+]?(?:\.inf|\.Inf|\.INF)|\.nan|\.NaN|\.NAN|[-+]?(?:0|[1-9][0-9_]*)(?:\.[0-9_]*)?(?:[eE][-+]?[0-9]+)?|0x[0-9a-fA-F_]+|0o?[0-7_]+)$`)
+)
+
+// yamlFrame is a mapping key or a sequence which is being parsed.
+type yamlFrame struct {
+	indent   int
+	key      string
+	sequence bool
+	index    int
+}
+
+// segment returns a path segment of the frame.
+func (f *yamlFrame) segment() string {
+	if f.sequence {
+		return strconv.Itoa(f.index)
+	}
+	return f.key
+}
+
+// yamlParser is a state of YAML parsing.
+type yamlParser struct {
+	doc      *document
+	lines    []string
+	offsets  []int  // start offsets of lines
+	crlf     []bool // lines which end with CRLF
+	stack    []*yamlFrame
+	rootKeys int
+}
+
+// indentOf returns a number of leading spaces.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// isYAMLSkipped returns true for empty lines, comments and document markers.
+func isYAMLSkipped(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" || trimmed == "..." ||
+		strings.HasPrefix(trimmed, "%")
+}
+
+// path returns a key path of the current stack.
+func (p *yamlParser) path() string {
+	segments := make([]string, len(p.stack))
+	for i, f := range p.stack {
+		segments[i] = f.segment()
+	}
+	return strings.Join(segments, ".")
+}
+
+// pop removes frames which are not parents of a line with the indent.
+func (p *yamlParser) pop(indent int, inclusive bool) {
+	for n := len(p.stack); n > 0; n = len(p.stack) {
+		f := p.stack[n-1]
+		if f.indent < indent || (!inclusive && f.indent == indent) {
+			break
+		}
+		p.stack = p.stack[:n-1]
+	}
+}
+
+// yamlEscapes are single character escape sequences of YAML double-quoted scalars.
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', '\t': '\t', 'n': '\n', 'v': '\v', 'f': '\f', 'r': '\r',
+	'e': 0x1b, ' ': ' ', '"': '"', '/': '/', '\\': '\\', 'N': 0x85, '_': 0xa0, 'L': 0x2028, 'P': 0x2029,
+}
+
+// yamlHexEscapes are lengths of hexadecimal codes of escape sequences.
+var yamlHexEscapes = map[byte]int{'x': 2, 'u': 4, 'U': 8}
+
+// unquoteYAML returns a text of single line double-quoted scalar by YAML escaping rules,
+// they differ from Go ones: "\x" is a code point, "\e", "\N", "\_", "\L", "\P" and "\ " are allowed.
+func unquoteYAML(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", fmt.Errorf("not double-quoted scalar")
+	}
+
+	var (
+		b strings.Builder
+		s = quoted[1 : len(quoted)-1]
+	)
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		if i++; i == len(s) {
+			return "", fmt.Errorf("unfinished escape sequence")
+		}
+
+		if r, ok := yamlEscapes[s[i]]; ok {
+			b.WriteRune(r)
+			continue
+		}
+
+		n, ok := yamlHexEscapes[s[i]]
+		if !ok {
+			return "", fmt.Errorf("unknown escape sequence %q", s[i-1:i+1])
+		}
+
+		if i+n >= len(s) {
+			return "", fmt.Errorf("short escape sequence %q", s[i-1:])
+		}
+
+		code, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", fmt.Errorf("invalid escape sequence %q", s[i-1:i+1+n])
+		}
+
+		b.WriteRune(rune(code))
+		i += n
+	}
+
+	return b.String(), nil
+}
+
+// quoteYAML returns a double-quoted scalar of the text,
+// control and line break characters are escaped by YAML rules.
+func quoteYAML(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case 0:
+			b.WriteString(`\0`)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		case 0x1b:
+			b.WriteString(`\e`)
+		case 0x85:
+			b.WriteString(`\N`)
+		case 0xa0:
+			b.WriteString(`\_`)
+		case 0x2028:
+			b.WriteString(`\L`)
+		case 0x2029:
+			b.WriteString(`\P`)
+		default:
+			if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+				fmt.Fprintf(&b, `\x%02X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteKey returns a key name.
+func unquoteKey(key string) string {
+	switch {
+	case strings.HasPrefix(key, `"`):
+		if s, err := unquoteYAML(key); err == nil {
+			return s
+		}
+	case strings.HasPrefix(key, "'"):
+		return strings.ReplaceAll(key[1:len(key)-1], "''", "'")
+	}
+	return key
+}
+
+// encodeYAMLPlain encodes a string as plain scalar if it is possible or as double-quoted one.
+func encodeYAMLPlain(s string) string {
+	unsafe := s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\t") ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") || strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") || strings.HasSuffix(s, ":") || yamlSpecialRegexp.MatchString(s)
+
+	if unsafe {
+		return quoteYAML(s)
+	}
+	return s
+}
+
+// encodeYAMLSingle encodes a string as single-quoted scalar.
+func encodeYAMLSingle(s string) string {
+	if strings.Contains(s, "\n") {
+		return quoteYAML(s)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quotedEnd returns a length of quoted scalar at the beginning of value or -1.
+func quotedEnd(value string) int {
+	quote := value[0]
+
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote:
+			if quote == '\'' && i+1 < len(value) && value[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// foldYAML folds line breaks of multi-line plain or quoted scalar like YAML parsers do:
+// a line break is a space, empty lines are line breaks, spaces around line breaks are removed.
+// Escaped line breaks of double-quoted scalars join lines without spaces.
+func foldYAML(s string, escaped bool) string {
+	var (
+		text  string
+		empty int
+		lines = strings.Split(s, "\n")
+	)
+
+	for j, line := range lines {
+		if j > 0 {
+			line = strings.TrimLeft(line, " \t")
+		}
+
+		if j < len(lines)-1 {
+			trimmed := strings.TrimRight(line, " \t")
+			if escaped && oddBackslashes(trimmed) && len(trimmed) < len(line) {
+				trimmed = line[:len(trimmed)+1] // escaped space or tab
+			}
+			line = trimmed
+		}
+
+		if j == 0 {
+			text = line
+			continue
+		}
+
+		if line == "" && j < len(lines)-1 {
+			empty++
+			continue
+		}
+
+		switch {
+		case escaped && oddBackslashes(text):
+			text = text[:len(text)-1]
+		case empty > 0:
+			text += strings.Repeat("\n", empty)
+		default:
+			text += " "
+		}
+
+		text += line
+		empty = 0
+	}
+
+	return text
+}
+
+// oddBackslashes returns true if the text ends with an escaping backslash.
+func oddBackslashes(text string) bool {
+	return (len(text)-len(strings.TrimRight(text, `\`)))%2 == 1
+}
+
+// plainLine returns a text of plain scalar line without a comment, the flag is true if there is a comment.
+func plainLine(line string) (string, bool) {
+	if k := strings.Index(line, " #"); k >= 0 {
+		return strings.TrimRight(line[:k], " \t"), true
+	}
+	return strings.TrimRight(line, " \t"), false
+}
+
+// scalar parses a value which starts at position start of the line i.
+// It returns an index of the next line to parse.
+func (p *yamlParser) scalar(i, start, indent int) (int, error) {
+	var (
+		trimmed = strings.TrimRight(p.lines[i][start:], " \t")
+		keyPath = p.path()
+	)
+
+	switch {
+	case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		return i + 1, nil // nested collection or null
+	case yamlBlockRegexp.MatchString(trimmed):
+		return p.block(i, indent, keyPath, trimmed[0] == '>')
+	case strings.ContainsAny(trimmed[:1], "&*![{"):
+		// anchors, aliases, tags and flow collections are not translated, also multi-line ones
+		return p.continued(i, indent) + 1, nil
+	case trimmed[0] == '"' || trimmed[0] == '\'':
+		return p.quoted(i, start, keyPath)
+	}
+
+	text, comment := plainLine(trimmed)
+	last, end := i, p.offsets[i]+start+len(text)
+
+	if !comment {
+		// continuation lines are more indented than the key, a comment finishes the scalar
+		for j, stop := i+1, p.continued(i, indent); j <= stop; j++ {
+			line, hasComment := plainLine(p.lines[j])
+			text += "\n" + line
+
+			if strings.TrimSpace(line) != "" {
+				last, end = j, p.offsets[j]+len(line)
+			}
+
+			if hasComment {
+				break
+			}
+		}
+		text = foldYAML(strings.TrimRight(text, " \t\n"), false)
+	}
+
+	if last > i || !yamlSpecialRegexp.MatchString(text) {
+		p.doc.leaves = append(p.doc.leaves, &leaf{
+			path:   keyPath,
+			text:   text,
+			start:  p.offsets[i] + start,
+			end:    end,
+			encode: encodeYAMLPlain,
+		})
+	}
+
+	return last + 1, nil
+}
+
+// continued returns an index of the last line which continues a scalar of the line i,
+// it is i if the scalar is not continued. Continuation lines are more indented than the key.
+func (p *yamlParser) continued(i, indent int) int {
+	last := i
+
+	for j := i + 1; j < len(p.lines); j++ {
+		line := p.lines[j]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if indentOf(line) <= indent || isYAMLSkipped(line) {
+			break
+		}
+		last = j
+	}
+
+	return last
+}
+
+// quoted parses single or double-quoted scalar which starts at position start of the line i,
+// the closing quote can be on one of the next lines. It returns an index of the next line to parse.
+func (p *yamlParser) quoted(i, start int, keyPath string) (int, error) {
+	var (
+		raw  = p.lines[i][start:]
+		last = i
+		end  = quotedEnd(raw)
+	)
+
+	for end < 0 && last+1 < len(p.lines) {
+		last++
+		raw += "\n" + p.lines[last]
+		end = quotedEnd(raw)
+	}
+
+	if end < 0 {
+		return 0, fmt.Errorf("line %d: not closed quoted scalar of key %q", i+1, keyPath)
+	}
+
+	// position of the closing quote end at the last line
+	column := end - strings.LastIndex(raw[:end], "\n") - 1
+	if last == i {
+		column += start
+	}
+
+	if rest := strings.TrimSpace(p.lines[last][column:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return 0, fmt.Errorf("line %d: unexpected text after quoted scalar of key %q", last+1, keyPath)
+	}
+
+	var (
+		double = raw[0] == '"'
+		text   = foldYAML(raw[1:end-1], double)
+		item   = &leaf{path: keyPath, start: p.offsets[i] + start, end: p.offsets[last] + column, encode: encodeYAMLSingle}
+	)
+
+	if double {
+		unquoted, err := unquoteYAML(`"` + text + `"`)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: invalid double-quoted scalar of key %q: %w", i+1, keyPath, err)
+		}
+		item.text, item.encode = unquoted, quoteYAML
+	} else {
+		item.text = strings.ReplaceAll(text, "''", "'")
+	}
+
+	p.doc.leaves = append(p.doc.leaves, item)
+	return last + 1, nil
+}
+
+// block parses literal or folded block scalar which header is on the line i.
+func (p *yamlParser) block(i, indent int, keyPath string, folded bool) (int, error) {
+	var (
+		end         = i + 1
+		blockIndent = -1
+	)
+
+	for ; end < len(p.lines); end++ {
+		line := p.lines[end]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		n := indentOf(line)
+		if n <= indent {
+			break
+		}
+
+		if blockIndent < 0 {
+			blockIndent = n
+		}
+	}
+
+	// trailing empty lines are not a part of the block content
+	for end > i+1 && strings.TrimSpace(p.lines[end-1]) == "" {
+		end--
+	}
+
+	if blockIndent < 0 {
+		return end, nil // empty block
+	}
+
+	lines := make([]string, 0, end-i-1)
+	for _, line := range p.lines[i+1 : end] {
+		if len(line) >= blockIndent {
+			line = line[blockIndent:]
+		} else {
+			line = ""
+		}
+		lines = append(lines, line)
+	}
+
+	text := strings.Join(lines, "\n")
+	if folded {
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\n\n", "\x00"), "\n", " ")
+		text = strings.ReplaceAll(text, "\x00", "\n")
+	}
+
+	// line breaks of the block are kept, the last one is outside of the value
+	eol := "\n"
+	if p.crlf[i] {
+		eol = "\r\n"
+	}
+
+	prefix := strings.Repeat(" ", blockIndent)
+	encode := func(s string) string {
+		if folded {
+			s = strings.ReplaceAll(s, "\n", "\n\n")
+		}
+
+		items := strings.Split(s, "\n")
+		for j, item := range items {
+			if item != "" {
+				items[j] = prefix + item
+			}
+		}
+		return strings.Join(items, eol)
+	}
+
+	start := p.offsets[i+1]
+	stop := p.offsets[end-1] + len(p.lines[end-1])
+	p.doc.leaves = append(p.doc.leaves, &leaf{path: keyPath, text: text, start: start, end: stop, encode: encode})
+
+	return end, nil
+}
+
+// line parses a line i, it returns an index of the next line to parse.
+func (p *yamlParser) line(i int) (int, error) {
+	var (
+		line   = p.lines[i]
+		start  = indentOf(line)
+		indent = start
+		parent = start // indent of a key or a sequence dash, continuation lines of values are more indented
+	)
+
+	if strings.HasPrefix(line[start:], "\t") {
+		return 0, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+	}
+
+	// sequence items, also nested ones like "- - value"
+	for {
+		m := yamlDashRegexp.FindString(line[start:])
+		if m == "" {
+			break
+		}
+
+		p.pop(indent, false)
+		if n := len(p.stack); n > 0 && p.stack[n-1].sequence && p.stack[n-1].indent == indent {
+			p.stack[n-1].index++
+		} else {
+			p.stack = append(p.stack, &yamlFrame{indent: indent, sequence: true})
+		}
+
+		parent = indent
+		start += len(m)
+		indent = start
+	}
+
+	if m := yamlKeyRegexp.FindStringSubmatch(line[start:]); m != nil {
+		p.pop(indent, true)
+
+		if len(p.stack) == 0 {
+			p.rootKeys++
+			p.doc.root = nil
+
+			if p.rootKeys == 1 {
+				p.doc.root = &leaf{
+					text:   unquoteKey(m[1]),
+					start:  p.offsets[i] + start,
+					end:    p.offsets[i] + start + len(m[1]),
+					encode: encodeYAMLPlain,
+				}
+			}
+		}
+
+		p.stack = append(p.stack, &yamlFrame{indent: indent, key: unquoteKey(m[1])})
+		parent = indent
+		start += len(m[0])
+	}
+
+	return p.scalar(i, start, parent)
+}
+
+// parseYAML parses YAML bundle data keeping positions of string values.
+func parseYAML(data string) (*document, error) {
+	p := &yamlParser{doc: &document{}, lines: strings.Split(data, "\n")}
+
+	p.offsets, p.crlf = make([]int, len(p.lines)), make([]bool, len(p.lines))
+	for i, offset := 0, 0; i < len(p.lines); i++ {
+		p.offsets[i] = offset
+		offset += len(p.lines[i]) + 1
+		p.lines[i], p.crlf[i] = strings.CutSuffix(p.lines[i], "\r")
+	}
+
+	for i := 0; i < len(p.lines); {
+		if isYAMLSkipped(p.lines[i]) {
+			i++
+			continue
+		}
+
+		next, err := p.line(i)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+	}
+
+	return p.doc, nil
+}
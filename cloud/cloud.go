@@ -6,10 +6,12 @@ import (
 	"context"
 	"crypto/rsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -30,6 +32,42 @@ var ps256WithSaltLengthEqualsHash = &jwt.SigningMethodRSAPSS{
 	Options:          &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash},
 }
 
+// StatusError is an error of not successful response status.
+type StatusError struct {
+	Code   int
+	Status string
+	Body   []byte
+}
+
+// Error is an implementation of error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request status %s: %s", e.Status, e.Body)
+}
+
+// ErrUnsupported is an error of a request which the service doesn't support, like a not configured language pair.
+var ErrUnsupported = errors.New("not supported by the service")
+
+// Unavailable returns true if the error is a transport one, a server side error (5xx)
+// or an unsupported request, so the request can be repeated by another service.
+// Canceled requests and requests with expired deadline of the caller are not repeated.
+func Unavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, ErrUnsupported) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
 // Account is API cloud struct info.
 type Account struct {
 	FolderID         string `json:"folder_id"`
@@ -107,7 +145,12 @@ func (a *Account) SetIAMToken(ctx context.Context, client *http.Client, userAgen
 }
 
 func buildRequest(ctx context.Context, data io.Reader, uri, bearer, userAgent string, isJSON bool) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, data)
+	method := http.MethodPost
+	if data == nil {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, data)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +169,7 @@ func buildRequest(ctx context.Context, data io.Reader, uri, bearer, userAgent st
 	return req, nil
 }
 
-// Request does POST request.
+// Request does POST request or GET one if data is nil.
 func Request(ctx context.Context, client *http.Client, data io.Reader, uri, bearer, userAgent string, isJSON bool, logger *log.Logger) ([]byte, error) {
 	req, err := buildRequest(ctx, data, uri, bearer, userAgent, isJSON)
 	if err != nil {
@@ -155,7 +198,7 @@ func Request(ctx context.Context, client *http.Client, data io.Reader, uri, bear
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request status %s: %s", resp.Status, body)
+		return nil, &StatusError{Code: resp.StatusCode, Status: resp.Status, Body: body}
 	}
 
 	return body, nil
@@ -13,8 +13,10 @@ import (
 	"net/http/httptest"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 const userAgent = "test/1.0"
@@ -76,6 +78,76 @@ func TestRequest(t *testing.T) {
 	}
 }
 
+func TestRequest_Get(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if _, err := fmt.Fprint(w, `[]`); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer s.Close()
+
+	data, err := Request(context.Background(), s.Client(), nil, s.URL, "", userAgent, true, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "[]" {
+		t.Errorf("unexpected response: %s", data)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(code)
+	}))
+	defer s.Close()
+
+	// not available server
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	request := func(ctx context.Context, uri string) error {
+		_, err := Request(ctx, s.Client(), nil, uri, "", userAgent, true, logger)
+		return err
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil"},
+		{name: "other", err: fmt.Errorf("other error")},
+		{name: "unsupported", err: fmt.Errorf("no pair: %w", ErrUnsupported), expected: true},
+		{name: "server", err: request(context.Background(), s.URL+"/503"), expected: true},
+		{name: "client", err: request(context.Background(), s.URL+"/403")},
+		{name: "canceled", err: request(canceled, s.URL+"/503")},
+		{name: "deadline", err: request(expired, s.URL+"/503")},
+		{name: "transport", err: fmt.Errorf("wrapped: %w", request(context.Background(), closed.URL)), expected: true},
+	}
+
+	for _, tc := range testCases {
+		if result := Unavailable(tc.err); result != tc.expected {
+			t.Errorf("%s: expected %v for error %v", tc.name, tc.expected, tc.err)
+		}
+	}
+}
+
 func TestAccount_SetIAMToken(t *testing.T) {
 	const tokenValue = "abc123"
 
@@ -15,18 +15,82 @@ import (
 	"github.com/z0rr0/ytapigo/cloud"
 )
 
+// DefaultLanguagesTTL is a default lifetime of cached supported languages lists.
+const DefaultLanguagesTTL = 7 * 24 * time.Hour
+
+// DefaultCooldown is a default period when failed providers are not used.
+const DefaultCooldown = 5 * time.Minute
+
+// Names is an ordered list of providers names, the next provider is used if the previous one is unavailable.
+// It is set by one name or by a list of names in configuration.
+type Names []string
+
+// UnmarshalJSON is an implementation of json.Unmarshaler interface.
+func (n *Names) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*n = nil
+		if name != "" {
+			*n = Names{name}
+		}
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("providers names must be a string or a list of strings: %w", err)
+	}
+
+	*n = names
+	return nil
+}
+
+// Providers are names of translation, dictionary and spelling check backends, Yandex is used if names are empty.
+type Providers struct {
+	Translation Names `json:"translation"`
+	Dictionary  Names `json:"dictionary"`
+	Spelling    Names `json:"spelling"`
+}
+
+// LibreTranslate is a LibreTranslate compatible server, API key is optional.
+type LibreTranslate struct {
+	URL    string `json:"url"`
+	APIKey string `json:"api_key"`
+}
+
+// DictionaryOptions are dictionary lookup parameters: a language of parts of speech names and notes
+// and lookup flags names (family, short_pos, morpho, pos_filter).
+type DictionaryOptions struct {
+	UI    string   `json:"ui"`
+	Flags []string `json:"flags"`
+}
+
 // Config is a struct of used services.
 type Config struct {
 	sync.Mutex
-	Translation cloud.Account `json:"translation"`
-	UserAgent   string        `json:"user_agent"`
-	ProxyURL    string        `json:"proxy_url"`
-	Dictionary  string        `json:"dictionary"`
-	AuthCache   string        `json:"auth_cache"`
-	Debug       bool          `json:"debug"`
-	Proxy       func(*http.Request) (*url.URL, error)
-	Logger      *log.Logger
-	URL         map[string]string // override URLs map for testing only
+	Translation    cloud.Account                `json:"translation"`
+	UserAgent      string                       `json:"user_agent"`
+	ProxyURL       string                       `json:"proxy_url"`
+	Dictionary     string                       `json:"dictionary"`
+	Lookup         map[string]DictionaryOptions `json:"lookup"` // default dictionary lookup options by language pairs like "en-ru"
+	Providers      Providers                    `json:"providers"`
+	LibreTranslate LibreTranslate               `json:"libretranslate"`
+	Offline        map[string]string            `json:"offline"` // local StarDict or dictd files by dictionary language pairs
+	AuthCache      string                       `json:"auth_cache"`
+	LanguagesCache string                       `json:"languages_cache"`  // supported languages lists cache file, no cache if empty
+	LanguagesTTL   string                       `json:"languages_ttl"`    // lifetime of cached languages lists like "24h"
+	HealthCache    string                       `json:"health_cache"`     // providers failures file, failures are kept during one run if empty
+	Cooldown       string                       `json:"cooldown"`         // period when failed providers are not used like "5m"
+	Memory         string                       `json:"memory"`           // translation memory file, no memory if empty
+	Threshold      float64                      `json:"memory_threshold"` // min similarity of suggested translation memory entries
+	Placeholders   []string                     `json:"placeholders"`     // regular expressions of user defined protected tokens
+	Debug          bool                         `json:"debug"`
+	Refresh        bool                         `json:"-"` // don't read cached data, but update it
+	Proxy          func(*http.Request) (*url.URL, error)
+	Logger         *log.Logger
+	URL            map[string]string // override URLs map for testing only
+	languagesTTL   time.Duration
+	cooldown       time.Duration
 }
 
 // New reads configuration file.
@@ -52,6 +116,15 @@ func New(fileName, configDir, cacheDir string, noCache, debug bool, logger *log.
 		return nil, err
 	}
 
+	if err = cfg.setLanguagesTTL(); err != nil {
+		return nil, err
+	}
+
+	if err = cfg.setCooldown(); err != nil {
+		return nil, err
+	}
+
+	cfg.Refresh = noCache
 	if noCache {
 		return cfg, nil // don't read cache, but write after data load
 	}
@@ -75,7 +148,7 @@ func (c *Config) setLogger(logger *log.Logger, debug bool) {
 	c.Logger = logger
 }
 
-// setFiles sets paths for key file and auth cache.
+// setFiles sets paths for key file, auth and languages caches and translation memory.
 func (c *Config) setFiles(configDir, cacheDir string) error {
 	c.Lock()
 	defer c.Unlock()
@@ -84,7 +157,24 @@ func (c *Config) setFiles(configDir, cacheDir string) error {
 		c.Translation.KeyFile = filepath.Join(configDir, c.Translation.KeyFile)
 	}
 
-	if cacheDir == "" || c.AuthCache == "" || filepath.IsAbs(c.AuthCache) {
+	for pair, fileName := range c.Offline {
+		if fileName != "" && !filepath.IsAbs(fileName) {
+			c.Offline[pair] = filepath.Join(configDir, fileName)
+		}
+	}
+
+	for _, fileName := range []*string{&c.AuthCache, &c.LanguagesCache, &c.HealthCache, &c.Memory} {
+		if err := cacheFile(cacheDir, fileName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cacheFile sets a path of the file in cache directory if it's relative, the directory is created if needed.
+func cacheFile(cacheDir string, fileName *string) error {
+	if cacheDir == "" || *fileName == "" || filepath.IsAbs(*fileName) {
 		// no cache or it has absolute path
 		return nil
 	}
@@ -104,7 +194,7 @@ func (c *Config) setFiles(configDir, cacheDir string) error {
 	}
 
 	// cacheDir is a directory and it exists
-	c.AuthCache = filepath.Join(cacheDir, c.AuthCache)
+	*fileName = filepath.Join(cacheDir, *fileName)
 	return nil
 }
 
@@ -131,6 +221,66 @@ func (c *Config) setProxy() error {
 	return nil
 }
 
+// setLanguagesTTL parses a lifetime of cached languages lists.
+func (c *Config) setLanguagesTTL() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.LanguagesTTL == "" {
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(c.LanguagesTTL)
+	if err != nil {
+		return fmt.Errorf("parse languages ttl: %w", err)
+	}
+
+	if ttl <= 0 {
+		return fmt.Errorf("languages ttl must be positive: %q", c.LanguagesTTL)
+	}
+
+	c.languagesTTL = ttl
+	return nil
+}
+
+// LanguagesLifetime returns a lifetime of cached languages lists.
+func (c *Config) LanguagesLifetime() time.Duration {
+	if c.languagesTTL > 0 {
+		return c.languagesTTL
+	}
+	return DefaultLanguagesTTL
+}
+
+// setCooldown parses a period when failed providers are not used.
+func (c *Config) setCooldown() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.Cooldown == "" {
+		return nil
+	}
+
+	cooldown, err := time.ParseDuration(c.Cooldown)
+	if err != nil {
+		return fmt.Errorf("parse cooldown: %w", err)
+	}
+
+	if cooldown <= 0 {
+		return fmt.Errorf("cooldown must be positive: %q", c.Cooldown)
+	}
+
+	c.cooldown = cooldown
+	return nil
+}
+
+// CooldownPeriod returns a period when failed providers are not used.
+func (c *Config) CooldownPeriod() time.Duration {
+	if c.cooldown > 0 {
+		return c.cooldown
+	}
+	return DefaultCooldown
+}
+
 // InitToken sets IAM token if it's empty.
 func (c *Config) InitToken(ctx context.Context, client *http.Client) error {
 	c.Lock()
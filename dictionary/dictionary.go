@@ -8,10 +8,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/z0rr0/ytapigo/cloud"
 	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/langtag"
 )
 
 // TranslationURL is a URL for translation request.
@@ -51,8 +53,9 @@ type Article struct {
 
 // Response is a type of translation dictionary (from API response).
 type Response struct {
-	Head map[string]string `json:"head"`
-	Def  []Article         `json:"def"`
+	Head    map[string]string `json:"head"`
+	Def     []Article         `json:"def"`
+	compact bool              // one line per translation
 }
 
 // Exists is an implementation of Exists() method for Response.
@@ -63,6 +66,10 @@ func (r *Response) Exists() bool {
 // String is an implementation of String() method for Response.
 // It returns a pretty formatted string.
 func (r *Response) String() string {
+	if r.compact {
+		return r.compactString()
+	}
+
 	var (
 		result, arResult, syn, mean, ex, extr []string
 		txtResult, txtSyn, txtMean, txtEx     string
@@ -74,12 +81,15 @@ func (r *Response) String() string {
 			ts = fmt.Sprintf(" [%v] ", def.Ts)
 		}
 		txtResult = fmt.Sprintf("%v%v(%v)", def.Text, ts, def.Pos)
+		if def.Pos == "" {
+			txtResult = strings.TrimSpace(def.Text + ts) // offline dictionaries have no parts of speech
+		}
 		arResult = make([]string, len(def.Tr))
 		for j, tr := range def.Tr {
 			syn, mean, ex = make([]string, len(tr.Syn)), make([]string, len(tr.Mean)), make([]string, len(tr.Ex))
 			txtSyn, txtMean, txtEx = "", "", ""
 			for k, s := range tr.Syn {
-				syn[k] = fmt.Sprintf("%v (%v)", s.Text, s.Pos)
+				syn[k] = withPos(s.Text, s.Pos)
 			}
 			for k, v := range tr.Mean {
 				mean[k] = v["text"]
@@ -101,25 +111,76 @@ func (r *Response) String() string {
 				txtEx = fmt.Sprintf("\n\texamples: \n\t\t%v", strings.Join(ex, "\n\t\t"))
 			}
 
-			arResult[j] = fmt.Sprintf("\t%v (%v)%v%v%v", tr.Text, tr.Pos, txtSyn, txtMean, txtEx)
+			arResult[j] = fmt.Sprintf("\t%v%v%v%v", withPos(tr.Text, tr.Pos), txtSyn, txtMean, txtEx)
 		}
 		result[i] = fmt.Sprintf("%v\n%v", txtResult, strings.Join(arResult, "\n"))
 	}
 	return strings.Join(result, "\n")
 }
 
+// withPos returns a text with its part of speech if it's known.
+func withPos(text, pos string) string {
+	if pos == "" {
+		return text
+	}
+	return fmt.Sprintf("%v (%v)", text, pos)
+}
+
+// Lookup flags of dictionary request.
+const (
+	FlagFamily    = 0x0001 // apply the family search filter
+	FlagShortPos  = 0x0002 // display abbreviations of parts of speech
+	FlagMorpho    = 0x0004 // search by word form, like "went" for "go"
+	FlagPosFilter = 0x0008 // translations have the same part of speech as the search word
+)
+
+// flagNames are names of lookup flags for configuration and command line options.
+var flagNames = map[string]int{
+	"family":     FlagFamily,
+	"short_pos":  FlagShortPos,
+	"morpho":     FlagMorpho,
+	"pos_filter": FlagPosFilter,
+}
+
+// ParseFlags returns lookup flags bitmask by their case-insensitive names.
+func ParseFlags(names []string) (int, error) {
+	var flags int
+
+	for _, name := range names {
+		flag, ok := flagNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("unknown dictionary flag %q, expected: family, short_pos, morpho or pos_filter", name)
+		}
+		flags |= flag
+	}
+
+	return flags, nil
+}
+
 // Request is a type of translation request items.
+// UI is a language of parts of speech names and notes, Flags is a bitmask of lookup flags.
 type Request struct {
 	Key                string
 	Text               string
 	TargetLanguageCode string
 	SourceLanguageCode string
+	UI                 string
+	Flags              int
 }
 
+// reader returns request parameters, dictionary languages are primary ones: "sr-Latn" -> "sr".
 func (r *Request) reader() io.Reader {
-	lang := fmt.Sprintf("%s-%s", r.SourceLanguageCode, r.TargetLanguageCode)
+	lang := fmt.Sprintf("%s-%s", langtag.Base(r.SourceLanguageCode), langtag.Base(r.TargetLanguageCode))
 	params := url.Values{"lang": {lang}, "text": {r.Text}, "key": {r.Key}}
 
+	if r.UI != "" {
+		params.Set("ui", langtag.Base(r.UI))
+	}
+
+	if r.Flags != 0 {
+		params.Set("flags", strconv.Itoa(r.Flags))
+	}
+
 	return strings.NewReader(params.Encode())
 }
 
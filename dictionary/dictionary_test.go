@@ -3,6 +3,7 @@ package dictionary
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -73,3 +74,65 @@ func TestTranslate(t *testing.T) {
 		t.Errorf("expected %q, got %q", expected, rs)
 	}
 }
+
+func TestParseFlags(t *testing.T) {
+	testCases := []struct {
+		names    []string
+		expected int
+		err      string
+	}{
+		{},
+		{names: []string{"morpho"}, expected: FlagMorpho},
+		{names: []string{"FAMILY", " short_pos", "morpho", "pos_filter"}, expected: 15},
+		{names: []string{"family", "family"}, expected: FlagFamily},
+		{names: []string{"morph"}, err: `unknown dictionary flag "morph", expected: family, short_pos, morpho or pos_filter`},
+	}
+
+	for i, tc := range testCases {
+		flags, err := ParseFlags(tc.names)
+		if err != nil {
+			if e := err.Error(); e != tc.err {
+				t.Errorf("case %d: expected error %q, got %q", i, tc.err, e)
+			}
+			continue
+		}
+
+		if tc.err != "" {
+			t.Errorf("case %d: expected error %q", i, tc.err)
+		}
+
+		if flags != tc.expected {
+			t.Errorf("case %d: expected %d, got %d", i, tc.expected, flags)
+		}
+	}
+}
+
+func TestRequest_reader(t *testing.T) {
+	testCases := []struct {
+		name     string
+		request  Request
+		expected string
+	}{
+		{
+			name:     "default",
+			request:  Request{Key: "key", Text: "went", SourceLanguageCode: "en-GB", TargetLanguageCode: "ru"},
+			expected: "key=key&lang=en-ru&text=went",
+		},
+		{
+			name:     "options",
+			request:  Request{Key: "key", Text: "went", SourceLanguageCode: "en", TargetLanguageCode: "ru", UI: "ru-RU", Flags: FlagMorpho | FlagFamily},
+			expected: "flags=5&key=key&lang=en-ru&text=went&ui=ru",
+		},
+	}
+
+	for _, tc := range testCases {
+		data, err := io.ReadAll(tc.request.reader())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s := string(data); s != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, s)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/z0rr0/ytapigo/cloud"
 	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/langtag"
 )
 
 // LanguagesURL is a URL to load dictionary languages.
@@ -61,13 +62,14 @@ func (languages *Languages) Sort() {
 }
 
 // Contains is an implementation of Contains() method for Languages pointer (LangChecker interface).
+// Languages are compared by primary language subtags, so "en-GB" is checked as "en".
 func (languages *Languages) Contains(fromLanguage, toLanguage string) bool {
 	length := languages.Len()
 	if length == 0 {
 		return false
 	}
 
-	search := strings.ToLower(fmt.Sprintf("%s-%s", fromLanguage, toLanguage))
+	search := fmt.Sprintf("%s-%s", langtag.Base(fromLanguage), langtag.Base(toLanguage))
 	data := *languages
 
 	i := sort.Search(length, func(i int) bool { return data[i] >= search })
@@ -79,3 +81,27 @@ func (languages *Languages) Contains(fromLanguage, toLanguage string) bool {
 func (languages *Languages) Description() string {
 	return fmt.Sprintf("Length=%v\n%v", len(*languages), languages.String())
 }
+
+// Table returns dictionary languages grouped by source language, one line per source: "en -> de, en, ru".
+func (languages *Languages) Table() string {
+	var (
+		lines   []string
+		source  string
+		targets []string
+	)
+
+	for _, pair := range *languages {
+		from, to, _ := strings.Cut(pair, "-")
+		if from != source && len(targets) > 0 {
+			lines = append(lines, fmt.Sprintf("%v -> %v", source, strings.Join(targets, ", ")))
+			targets = nil
+		}
+		source, targets = from, append(targets, to)
+	}
+
+	if len(targets) > 0 {
+		lines = append(lines, fmt.Sprintf("%v -> %v", source, strings.Join(targets, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
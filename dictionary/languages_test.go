@@ -62,6 +62,8 @@ func TestLanguages_Contains(t *testing.T) {
 		{languages: Languages{"en-en", "en-ru"}, fromLang: "de", toLang: "en"},
 		{languages: Languages{"en-en", "en-ru"}, fromLang: "de"},
 		{languages: Languages{"en-en", "en-ru"}, toLang: "en"},
+		{languages: Languages{"en-en", "en-ru"}, fromLang: "en-GB", toLang: "ru-RU", expected: true},
+		{languages: Languages{"sr-ru"}, fromLang: "sr-Latn", toLang: "ru", expected: true},
 	}
 
 	for i, tc := range testCases {
@@ -70,3 +72,20 @@ func TestLanguages_Contains(t *testing.T) {
 		}
 	}
 }
+
+func TestLanguages_Table(t *testing.T) {
+	testCases := []struct {
+		languages Languages
+		expected  string
+	}{
+		{},
+		{languages: Languages{"en-ru"}, expected: "en -> ru"},
+		{languages: Languages{"de-en", "de-ru", "en-de", "en-en", "en-ru", "ru-en"}, expected: "de -> en, ru\nen -> de, en, ru\nru -> en"},
+	}
+
+	for i, tc := range testCases {
+		if table := tc.languages.Table(); table != tc.expected {
+			t.Errorf("%d: expected %q, got %q", i, tc.expected, table)
+		}
+	}
+}
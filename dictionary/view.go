@@ -0,0 +1,118 @@
+package dictionary
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// View are output options of dictionary articles.
+type View struct {
+	Pos        []string // shown parts of speech (or their prefixes), all if empty
+	MaxTr      int      // max translations of an article, 0 - no limit
+	MaxSyn     int      // max synonyms of a translation, 0 - no limit
+	SortSyn    bool     // sort synonyms by frequency
+	NoExamples bool     // hide examples
+	NoMeanings bool     // hide meanings
+	Compact    bool     // one line per translation with its synonyms and meanings
+}
+
+// matchPos returns true if the part of speech is shown.
+func (v *View) matchPos(pos string) bool {
+	if len(v.Pos) == 0 {
+		return true
+	}
+
+	pos = strings.ToLower(pos)
+	return pos != "" && slices.ContainsFunc(v.Pos, func(p string) bool {
+		return strings.HasPrefix(pos, strings.ToLower(strings.TrimSpace(p)))
+	})
+}
+
+// Apply returns a copy of the response with articles of selected parts of speech,
+// sorted and limited translations and synonyms, without hidden examples and meanings.
+func (r *Response) Apply(v *View) *Response {
+	result := &Response{Head: r.Head, compact: v.Compact}
+
+	for _, def := range r.Def {
+		if !v.matchPos(def.Pos) {
+			continue
+		}
+
+		tr := def.Tr
+		if v.MaxTr > 0 && len(tr) > v.MaxTr {
+			tr = tr[:v.MaxTr]
+		}
+
+		def.Tr = make([]TrItem, len(tr))
+		for i, item := range tr {
+			def.Tr[i] = item.apply(v)
+		}
+
+		result.Def = append(result.Def, def)
+	}
+
+	return result
+}
+
+// apply returns a copy of the translation with view options.
+func (item TrItem) apply(v *View) TrItem {
+	if v.SortSyn {
+		item.Syn = slices.Clone(item.Syn)
+		slices.SortStableFunc(item.Syn, func(a, b TextPosGen) int {
+			return cmp.Compare(b.Fr, a.Fr)
+		})
+	}
+
+	if v.MaxSyn > 0 && len(item.Syn) > v.MaxSyn {
+		item.Syn = item.Syn[:v.MaxSyn]
+	}
+
+	if v.NoExamples {
+		item.Ex = nil
+	}
+
+	if v.NoMeanings {
+		item.Mean = nil
+	}
+
+	return item
+}
+
+// compactString returns articles with one line per translation: its text, synonyms and meanings.
+func (r *Response) compactString() string {
+	var lines []string
+
+	for _, def := range r.Def {
+		header := def.Text
+		if def.Ts != "" {
+			header += fmt.Sprintf(" [%v]", def.Ts)
+		}
+
+		if def.Pos != "" {
+			header += fmt.Sprintf(" (%v)", def.Pos)
+		}
+
+		lines = append(lines, header)
+		for _, tr := range def.Tr {
+			words := []string{tr.Text}
+			for _, syn := range tr.Syn {
+				words = append(words, syn.Text)
+			}
+
+			line := "\t" + strings.Join(words, ", ")
+			if len(tr.Mean) > 0 {
+				means := make([]string, len(tr.Mean))
+				for i, mean := range tr.Mean {
+					means[i] = mean["text"]
+				}
+				line += fmt.Sprintf(" (%v)", strings.Join(means, ", "))
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
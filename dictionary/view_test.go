@@ -0,0 +1,81 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var viewResponse = `
+{ "def": [
+    { "text": "run", "pos": "noun", "ts": "rʌn",
+      "tr": [
+        { "text": "пробег", "pos": "noun",
+          "syn": [{"text": "забег", "pos": "noun", "fr": 1}, {"text": "бег", "pos": "noun", "fr": 5}],
+          "mean": [{"text": "race"}],
+          "ex": [{"text": "morning run", "tr": [{"text": "утренняя пробежка"}]}]
+        },
+        { "text": "серия", "pos": "noun" }
+      ]
+    },
+    { "text": "run", "pos": "verb", "ts": "rʌn",
+      "tr": [{ "text": "бежать", "pos": "verb", "syn": [{"text": "бегать", "pos": "verb", "fr": 10}] }]
+    }
+  ]
+}`
+
+func TestResponse_Apply(t *testing.T) {
+	r := &Response{}
+	if err := json.Unmarshal([]byte(viewResponse), r); err != nil {
+		t.Fatal(err)
+	}
+
+	full := r.String()
+	testCases := []struct {
+		name     string
+		view     View
+		expected string
+	}{
+		{name: "default", expected: full},
+		{
+			name:     "pos",
+			view:     View{Pos: []string{"VERB"}},
+			expected: "run [rʌn] (verb)\n\tбежать (verb)\n\tsyn: бегать (verb)",
+		},
+		{name: "pos_prefix", view: View{Pos: []string{"adj", "ver"}}, expected: "run [rʌn] (verb)\n\tбежать (verb)\n\tsyn: бегать (verb)"},
+		{name: "pos_not_found", view: View{Pos: []string{"adjective"}}},
+		{
+			name:     "limits",
+			view:     View{Pos: []string{"noun"}, MaxTr: 1, MaxSyn: 1, SortSyn: true, NoExamples: true},
+			expected: "run [rʌn] (noun)\n\tпробег (noun)\n\tsyn: бег (noun)\n\tmean: race",
+		},
+		{
+			name:     "no_meanings",
+			view:     View{Pos: []string{"noun"}, MaxTr: 1, NoMeanings: true},
+			expected: "run [rʌn] (noun)\n\tпробег (noun)\n\tsyn: забег (noun), бег (noun)\n\texamples: \n\t\tmorning run: утренняя пробежка",
+		},
+		{
+			name:     "compact",
+			view:     View{SortSyn: true, Compact: true},
+			expected: "run [rʌn] (noun)\n\tпробег, бег, забег (race)\n\tсерия\nrun [rʌn] (verb)\n\tбежать, бегать",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := r.Apply(&tc.view)
+
+			if s := result.String(); s != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, s)
+			}
+
+			if result.Exists() != (tc.expected != "") {
+				t.Errorf("unexpected exists %v", result.Exists())
+			}
+		})
+	}
+
+	// the source response is not changed
+	if s := r.String(); s != full {
+		t.Errorf("response is changed:\n%q", s)
+	}
+}
@@ -0,0 +1,112 @@
+// Package explain builds a per-word breakdown of a translated phrase.
+// Distinct content words of the phrase are glossed by their transcriptions and primary dictionary translations.
+package explain
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/langtag"
+)
+
+// MinLength is a min number of letters of a content word.
+const MinLength = 3
+
+// stopWords are function words of languages, they are not looked up in dictionaries.
+var stopWords = map[string][]string{
+	"en": {
+		"and", "are", "but", "can", "did", "does", "for", "from", "had", "has", "have", "her", "his", "its",
+		"not", "our", "she", "that", "the", "their", "them", "then", "there", "these", "they", "this", "those",
+		"was", "were", "what", "when", "where", "which", "who", "will", "with", "would", "you", "your",
+	},
+	"ru": {
+		"был", "была", "были", "было", "вот", "все", "всё", "для", "его", "ее", "её", "еще", "ещё", "или", "как",
+		"когда", "кто", "мне", "над", "нас", "него", "нет", "они", "оно", "под", "при", "так", "там", "тот",
+		"уже", "что", "чтобы", "это", "этот",
+	},
+}
+
+// Gloss is a short dictionary description of a word.
+type Gloss struct {
+	Word        string
+	Ts          string
+	Translation string
+}
+
+// String returns a gloss line like "word [ts] — translation".
+func (g Gloss) String() string {
+	var b strings.Builder
+	b.WriteString(g.Word)
+
+	if g.Ts != "" {
+		b.WriteString(" [" + g.Ts + "]")
+	}
+
+	b.WriteString(" — " + g.Translation)
+	return b.String()
+}
+
+// NewGloss returns a gloss of the word by the first article of the dictionary response.
+// It returns false if there are no translations.
+func NewGloss(word string, r *dictionary.Response) (Gloss, bool) {
+	for _, def := range r.Def {
+		if len(def.Tr) > 0 && def.Tr[0].Text != "" {
+			return Gloss{Word: word, Ts: def.Ts, Translation: def.Tr[0].Text}, true
+		}
+	}
+	return Gloss{}, false
+}
+
+// Result is a translation with glosses of its original words.
+type Result struct {
+	Translation string
+	Glosses     []Gloss
+}
+
+// Exists is an implementation of Exists() method for Result.
+func (r *Result) Exists() bool {
+	return r.Translation != ""
+}
+
+// String is an implementation of String() method for Result.
+func (r *Result) String() string {
+	var b strings.Builder
+	b.WriteString(r.Translation)
+
+	for _, g := range r.Glosses {
+		b.WriteString("\n\t" + g.String())
+	}
+
+	return b.String()
+}
+
+// Words returns distinct content words of the text in lower case, ordered by their first occurrence.
+// Short words, numbers and stop words of the language are skipped.
+func Words(text, language string) []string {
+	var (
+		stop  = stopWords[langtag.Base(language)]
+		words []string
+	)
+
+	for _, field := range strings.FieldsFunc(text, separator) {
+		word := strings.ToLower(strings.Trim(field, "-'’"))
+
+		if utf8.RuneCountInString(word) < MinLength || !strings.ContainsFunc(word, unicode.IsLetter) {
+			continue
+		}
+
+		if !slices.Contains(stop, word) && !slices.Contains(words, word) {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// separator returns true if the rune is not a part of a word, hyphens and apostrophes join compound words.
+func separator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-'’", r)
+}
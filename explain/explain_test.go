@@ -0,0 +1,65 @@
+package explain
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+)
+
+func TestWords(t *testing.T) {
+	testCases := []struct {
+		text     string
+		language string
+		expected []string
+	}{
+		{language: "en"},
+		{text: "The cat and the CAT", language: "en", expected: []string{"cat"}},
+		{text: "It's time to start, time-slot 100 is free!", language: "en-GB", expected: []string{"it's", "time", "start", "time-slot", "free"}},
+		{text: "The cat", language: "de", expected: []string{"the", "cat"}},
+		{text: "Это время, когда всё начинается", language: "ru", expected: []string{"время", "начинается"}},
+	}
+
+	for i, tc := range testCases {
+		if words := Words(tc.text, tc.language); !slices.Equal(words, tc.expected) {
+			t.Errorf("case %d: expected %q, got %q", i, tc.expected, words)
+		}
+	}
+}
+
+func TestNewGloss(t *testing.T) {
+	r := &dictionary.Response{Def: []dictionary.Article{
+		{Text: "time", Ts: "taɪm"},
+		{Text: "time", Ts: "taɪm", Tr: []dictionary.TrItem{{TextPosGen: dictionary.TextPosGen{Text: "время"}}}},
+	}}
+
+	g, ok := NewGloss("times", r)
+	if !ok {
+		t.Fatal("gloss is expected")
+	}
+
+	if s, expected := g.String(), "times [taɪm] — время"; s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	if _, ok = NewGloss("time", &dictionary.Response{}); ok {
+		t.Error("gloss is not expected")
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	r := &Result{Translation: "пора начинать", Glosses: []Gloss{{Word: "time", Ts: "taɪm", Translation: "время"}, {Word: "start", Translation: "начало"}}}
+	expected := "пора начинать\n\ttime [taɪm] — время\n\tstart — начало"
+
+	if s := r.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	if !r.Exists() {
+		t.Error("result is expected")
+	}
+
+	if (&Result{}).Exists() {
+		t.Error("empty result is not expected")
+	}
+}
@@ -0,0 +1,385 @@
+// Package gettext implements translation of gettext PO and POT catalogs.
+// Untranslated messages (including plural forms) are translated and marked as fuzzy,
+// comments, contexts and references are kept as is. Plural forms are translated by samples
+// with numbers of the forms from "Plural-Forms" header, like "5 files" for "%d files".
+package gettext
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// fuzzyFlag marks messages which should be reviewed by a translator.
+const fuzzyFlag = "fuzzy"
+
+var (
+	keywordRegexp = regexp.MustCompile(`^(msgctxt|msgid|msgid_plural|msgstr(?:\[(\d+)\])?)\s+(".*")\s*$`)
+	pluralsRegexp = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+)
+
+// entry is a catalog message.
+type entry struct {
+	head     []string // comments and msgctxt/msgid lines
+	tail     []string // msgstr lines
+	flags    int      // index of flags comment in head, -1 if it doesn't exist
+	obsolete bool
+	id       string
+	plural   string
+	str      []string
+}
+
+// unquote decodes a PO string.
+func unquote(s string) (string, error) {
+	value, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s: %w", s, err)
+	}
+	return value, nil
+}
+
+// quote encodes a value as PO string lines with keyword.
+func quote(keyword, value string) []string {
+	lines := strings.SplitAfter(value, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) < 2 {
+		return []string{keyword + " " + encode(value)}
+	}
+
+	result := make([]string, 0, len(lines)+1)
+	result = append(result, keyword+` ""`)
+	for _, line := range lines {
+		result = append(result, encode(line))
+	}
+
+	return result
+}
+
+// encode escapes a string in C-style.
+func encode(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// parseEntry parses one catalog message from its lines.
+func parseEntry(lines []string) (*entry, error) {
+	var (
+		e       = &entry{flags: -1, obsolete: true}
+		current *string
+		tail    = len(lines)
+	)
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if !strings.HasPrefix(trimmed, "#~") {
+			e.obsolete = false
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			if strings.HasPrefix(trimmed, "#,") && i < tail {
+				e.flags = i
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, `"`) {
+			if current == nil {
+				return nil, fmt.Errorf("unexpected string line %q", line)
+			}
+
+			value, err := unquote(trimmed)
+			if err != nil {
+				return nil, err
+			}
+
+			*current += value
+			continue
+		}
+
+		m := keywordRegexp.FindStringSubmatch(trimmed)
+		if m == nil {
+			return nil, fmt.Errorf("unexpected line %q", line)
+		}
+
+		value, err := unquote(m[3])
+		if err != nil {
+			return nil, err
+		}
+
+		switch m[1] {
+		case "msgctxt":
+			current = new(string) // context is kept as is
+		case "msgid":
+			current = &e.id
+		case "msgid_plural":
+			current = &e.plural
+		default:
+			n := 0
+			if m[2] != "" {
+				n, _ = strconv.Atoi(m[2])
+			}
+
+			if n != len(e.str) {
+				return nil, fmt.Errorf("unexpected plural form index %q", line)
+			}
+
+			tail = min(tail, i)
+			e.str = append(e.str, "")
+			current = &e.str[n]
+		}
+		*current = value
+	}
+
+	e.head, e.tail = lines[:tail], lines[tail:]
+	return e, nil
+}
+
+// isHeader returns true if the entry is catalog header.
+func (e *entry) isHeader() bool {
+	return e.id == "" && !e.obsolete
+}
+
+// translated returns true if all message translations are not empty.
+func (e *entry) translated() bool {
+	if len(e.str) == 0 {
+		return false
+	}
+
+	for _, s := range e.str {
+		if s == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setFuzzy adds fuzzy flag to the entry.
+func (e *entry) setFuzzy() {
+	if e.flags < 0 {
+		// flags comment goes after all other comments
+		i := 0
+		for i < len(e.head) && strings.HasPrefix(strings.TrimSpace(e.head[i]), "#") &&
+			!strings.HasPrefix(strings.TrimSpace(e.head[i]), "#|") {
+			i++
+		}
+
+		e.head = slices.Insert(e.head, i, "#, "+fuzzyFlag)
+		e.flags = i
+		return
+	}
+
+	flags := strings.Split(strings.TrimPrefix(strings.TrimSpace(e.head[e.flags]), "#,"), ",")
+	for _, flag := range flags {
+		if strings.TrimSpace(flag) == fuzzyFlag {
+			return
+		}
+	}
+
+	e.head[e.flags] = strings.TrimRight(e.head[e.flags], " \t") + ", " + fuzzyFlag
+}
+
+// setTranslations replaces message translations and marks the entry as fuzzy.
+func (e *entry) setTranslations(values []string) {
+	e.str = values
+	e.tail = nil
+
+	if e.plural == "" {
+		e.tail = quote("msgstr", values[0])
+	} else {
+		for i, value := range values {
+			e.tail = append(e.tail, quote(fmt.Sprintf("msgstr[%d]", i), value)...)
+		}
+	}
+
+	e.setFuzzy()
+}
+
+// catalog is a parsed PO file, blocks are entries or empty lines.
+type catalog struct {
+	entries []*entry
+	spaces  []string // empty lines before every entry
+	tail    string   // empty lines at the end of file
+	plurals int
+	form    pluralForm // plural expression of the header, it is nil if it's not set or not valid
+	samples []int      // sample numbers of plural forms
+}
+
+// parse parses PO file data.
+func parse(data string) (*catalog, error) {
+	var (
+		c     = &catalog{plurals: 2}
+		block []string
+		space string
+	)
+
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+
+		e, err := parseEntry(block)
+		if err != nil {
+			return err
+		}
+
+		if e.isHeader() && len(e.str) > 0 {
+			if m := pluralsRegexp.FindStringSubmatch(e.str[0]); m != nil {
+				c.plurals, _ = strconv.Atoi(m[1])
+			}
+
+			if m := pluralRegexp.FindStringSubmatch(e.str[0]); m != nil {
+				c.form, _ = parsePlural(m[1]) // forms without samples are translated as msgid_plural
+			}
+		}
+
+		c.entries = append(c.entries, e)
+		c.spaces = append(c.spaces, space)
+		block, space = nil, ""
+		return nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			block = append(block, line)
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		space += line + "\n"
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if c.form != nil {
+		c.samples = c.form.sampleNumbers(c.plurals)
+	}
+
+	c.tail = space
+	return c, nil
+}
+
+// String returns PO file data.
+func (c *catalog) String() string {
+	var b strings.Builder
+
+	for i, e := range c.entries {
+		b.WriteString(c.spaces[i])
+		for _, line := range e.head {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		for _, line := range e.tail {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(c.tail)
+	return b.String()
+}
+
+// Translate translates messages of PO or POT data by plain text translation function fn.
+// Messages which already have translations are skipped if force is false.
+func Translate(ctx context.Context, data []byte, force bool, fn translation.BatchFunc) ([]byte, error) {
+	source := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	c, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %w", err)
+	}
+
+	var (
+		entries []*entry
+		texts   []string
+		samples [][]*sample // samples of plural forms of entries, nil if a form has no sample
+	)
+
+	for _, e := range c.entries {
+		if e.isHeader() || e.obsolete || e.str == nil || (!force && e.translated()) {
+			continue
+		}
+
+		entries = append(entries, e)
+		texts = append(texts, e.id)
+
+		var forms []*sample
+		if e.plural != "" {
+			texts = append(texts, e.plural)
+			forms = make([]*sample, max(c.plurals, len(e.str), 1))
+
+			for j := 1; j < min(len(forms), len(c.samples)); j++ {
+				if s, ok := newSample(c.samples[j], e.plural); ok {
+					forms[j] = &s
+					texts = append(texts, s.text)
+				}
+			}
+		}
+		samples = append(samples, forms)
+	}
+
+	translations, err := fn(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(translations) != len(texts) {
+		return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(texts))
+	}
+
+	i := 0
+	for k, e := range entries {
+		if e.plural == "" {
+			e.setTranslations(translations[i : i+1])
+			i++
+			continue
+		}
+
+		var (
+			forms  = samples[k]
+			values = make([]string, len(forms))
+			plural = translations[i+1]
+		)
+		values[0], i = translations[i], i+2
+
+		// other forms are translations of msgid_plural samples with their numbers replaced back by format verbs,
+		// a form without a sample or with a lost number gets msgid_plural translation and needs review
+		for j := 1; j < len(values); j++ {
+			values[j] = plural
+			if forms[j] == nil {
+				continue
+			}
+
+			if text, ok := forms[j].restore(translations[i]); ok {
+				values[j] = text
+			}
+			i++
+		}
+
+		e.setTranslations(values)
+	}
+
+	result := c.String()
+	if !strings.HasSuffix(source, "\n") {
+		result = strings.TrimSuffix(result, "\n")
+	}
+
+	if strings.Contains(string(data), "\r\n") {
+		result = strings.ReplaceAll(result, "\n", "\r\n")
+	}
+
+	return []byte(result), nil
+}
@@ -0,0 +1,220 @@
+package gettext
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/internal/testfn"
+)
+
+const source = `# Translation file.
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#. extracted comment
+#: main.go:10
+msgctxt "menu"
+msgid "Open file"
+msgstr ""
+
+#: main.go:20
+#, c-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+#: main.go:30
+msgid ""
+"Multi-line\n"
+"text"
+msgstr ""
+
+msgid "done"
+msgstr "готово"
+
+#~ msgid "old"
+#~ msgstr ""
+`
+
+func TestTranslate(t *testing.T) {
+	expected := `# Translation file.
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#. extracted comment
+#: main.go:10
+#, fuzzy
+msgctxt "menu"
+msgid "Open file"
+msgstr "OPEN FILE"
+
+#: main.go:20
+#, c-format, fuzzy
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%D FILE"
+msgstr[1] "%d FILES"
+msgstr[2] "%d FILES"
+
+#: main.go:30
+#, fuzzy
+msgid ""
+"Multi-line\n"
+"text"
+msgstr ""
+"MULTI-LINE\n"
+"TEXT"
+
+msgid "done"
+msgstr "готово"
+
+#~ msgid "old"
+#~ msgstr ""
+`
+
+	result, err := Translate(context.Background(), []byte(source), false, testfn.Upper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := string(result); r != expected {
+		t.Errorf("unexpected result:\n%s", r)
+	}
+
+	result, err = Translate(context.Background(), []byte(source), true, testfn.Upper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = strings.Replace(expected, "msgid \"done\"\nmsgstr \"готово\"", "#, fuzzy\nmsgid \"done\"\nmsgstr \"DONE\"", 1)
+	if r := string(result); r != expected {
+		t.Errorf("unexpected forced result:\n%s", r)
+	}
+}
+
+func TestTranslate_Error(t *testing.T) {
+	testCases := []string{
+		"msgid \"a\"\nunknown\n",
+		"\"string without keyword\"\n",
+		"msgid \"a\nmsgstr \"\"\n",
+		"msgid \"a\"\nmsgstr[1] \"\"\n",
+	}
+
+	for i, tc := range testCases {
+		if _, err := Translate(context.Background(), []byte(tc), false, testfn.Upper); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestTranslate_Plurals(t *testing.T) {
+	const catalog = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\\n"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+msgid "one file"
+msgid_plural "many files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+msgid "%d new file"
+msgid_plural "%d new files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+`
+	translations := map[string]string{
+		"%d file":      "%d файл",
+		"%d files":     "%d файлов",
+		"2 files":      "2 файла",
+		"5 files":      "5 файлов",
+		"one file":     "один файл",
+		"many files":   "много файлов",
+		"%d new file":  "%d новый файл",
+		"%d new files": "%d новых файлов",
+		"2 new files":  "два новых файла", // the number is lost
+		"5 new files":  "5 новых файлов",
+	}
+
+	fn := func(_ context.Context, texts []string) ([]string, error) {
+		result := make([]string, len(texts))
+		for i, text := range texts {
+			result[i] = translations[text]
+		}
+		return result, nil
+	}
+
+	result, err := Translate(context.Background(), []byte(catalog), false, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"msgstr[0] \"%d файл\"\nmsgstr[1] \"%d файла\"\nmsgstr[2] \"%d файлов\"\n",
+		"msgstr[0] \"один файл\"\nmsgstr[1] \"много файлов\"\nmsgstr[2] \"много файлов\"\n",
+		"msgstr[0] \"%d новый файл\"\nmsgstr[1] \"%d новых файлов\"\nmsgstr[2] \"%d новых файлов\"\n",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(string(result), e) {
+			t.Errorf("expected %q in result:\n%s", e, result)
+		}
+	}
+}
+
+func TestParsePlural(t *testing.T) {
+	testCases := []struct {
+		expression string
+		expected   []int // forms of numbers from 0
+		err        bool
+	}{
+		{expression: "n != 1", expected: []int{1, 0, 1, 1}},
+		{expression: "0", expected: []int{0, 0, 0}},
+		{expression: "(n > 1)", expected: []int{0, 0, 1}},
+		{
+			expression: "n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5",
+			expected:   []int{0, 1, 2, 3, 3, 3, 3, 3, 3, 3, 3, 4},
+		},
+		{expression: "!(n == 1) + n / 0", expected: []int{1, 0, 1}},
+		{expression: "n %", err: true},
+		{expression: "n ? 1", err: true},
+		{expression: "(n", err: true},
+		{expression: "n = 1", err: true},
+		{expression: "n 1", err: true},
+	}
+
+	for _, tc := range testCases {
+		form, err := parsePlural(tc.expression)
+		if err != nil {
+			if !tc.err {
+				t.Errorf("unexpected error for %q: %v", tc.expression, err)
+			}
+			continue
+		}
+
+		if tc.err {
+			t.Errorf("expected error for %q", tc.expression)
+			continue
+		}
+
+		for n, expected := range tc.expected {
+			if i := form(n); i != expected {
+				t.Errorf("%q: expected form %d of %d, got %d", tc.expression, expected, n, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package gettext
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxSample is a max number which is checked to find a sample of a plural form.
+const maxSample = 1000
+
+var (
+	pluralRegexp = regexp.MustCompile(`\bplural\s*=\s*([^;\n]+)`)
+	tokenRegexp  = regexp.MustCompile(`^\s*(\d+|n|&&|\|\||[=!<>]=|[-+*/%<>!?:()])`)
+	numberRegexp = regexp.MustCompile(`\d+`)
+	// verbRegexp is a number format verb of a message like "%d", "%1$d", "%ld" or Python "%(count)d".
+	verbRegexp = regexp.MustCompile(`%(?:\d+\$)?[-+ 0#']*\d*(?:hh|h|ll|l|j|z|t)?[diu]|%\([^)]+\)[-+ 0#]*\d*d`)
+)
+
+// pluralForm returns an index of the plural form of the number.
+type pluralForm func(n int) int
+
+// pluralParser is a state of plural expression parsing, C operators precedence is used.
+type pluralParser struct {
+	tokens []string
+	pos    int
+}
+
+// parsePlural compiles a plural expression of the catalog header like "n%10==1 && n%100!=11 ? 0 : 1".
+func parsePlural(expression string) (pluralForm, error) {
+	p := &pluralParser{}
+	rest := strings.TrimSpace(expression)
+
+	for rest != "" {
+		m := tokenRegexp.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("unexpected plural expression %q", rest)
+		}
+		p.tokens = append(p.tokens, m[1])
+		rest = strings.TrimSpace(rest[len(m[0]):])
+	}
+
+	fn, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected plural expression token %q", p.tokens[p.pos])
+	}
+
+	return pluralForm(fn), nil
+}
+
+// next returns the current token and moves to the next one if it is one of expected ones.
+func (p *pluralParser) next(expected ...string) (string, bool) {
+	if p.pos < len(p.tokens) {
+		for _, token := range expected {
+			if p.tokens[p.pos] == token {
+				p.pos++
+				return token, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ternary parses "condition ? a : b" expression.
+func (p *pluralParser) ternary() (func(int) int, error) {
+	condition, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.next("?"); !ok {
+		return condition, nil
+	}
+
+	a, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.next(":"); !ok {
+		return nil, fmt.Errorf("expected ':' in plural expression")
+	}
+
+	b, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(n int) int {
+		if condition(n) != 0 {
+			return a(n)
+		}
+		return b(n)
+	}, nil
+}
+
+// binaryLevels are binary operators by precedence from the lowest one.
+var binaryLevels = [][]string{{"||"}, {"&&"}, {"==", "!="}, {"<", ">", "<=", ">="}, {"+", "-"}, {"*", "/", "%"}}
+
+// binary parses left associative binary operators of the precedence level and higher ones.
+func (p *pluralParser) binary(level int) (func(int) int, error) {
+	if level == len(binaryLevels) {
+		return p.unary()
+	}
+
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.next(binaryLevels[level]...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = operation(op, left, right)
+	}
+}
+
+// operation returns a function of the binary operator, division by zero returns zero.
+func operation(op string, a, b func(int) int) func(int) int {
+	boolean := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+
+	return func(n int) int {
+		x, y := a(n), b(n)
+
+		switch op {
+		case "||":
+			return boolean(x != 0 || y != 0)
+		case "&&":
+			return boolean(x != 0 && y != 0)
+		case "==":
+			return boolean(x == y)
+		case "!=":
+			return boolean(x != y)
+		case "<":
+			return boolean(x < y)
+		case ">":
+			return boolean(x > y)
+		case "<=":
+			return boolean(x <= y)
+		case ">=":
+			return boolean(x >= y)
+		case "+":
+			return x + y
+		case "-":
+			return x - y
+		case "*":
+			return x * y
+		}
+
+		if y == 0 {
+			return 0
+		}
+
+		if op == "/" {
+			return x / y
+		}
+		return x % y
+	}
+}
+
+// unary parses negation, a number, "n" or an expression in parentheses.
+func (p *pluralParser) unary() (func(int) int, error) {
+	if _, ok := p.next("!"); ok {
+		fn, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(n int) int {
+			if fn(n) == 0 {
+				return 1
+			}
+			return 0
+		}, nil
+	}
+
+	if _, ok := p.next("("); ok {
+		fn, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok = p.next(")"); !ok {
+			return nil, fmt.Errorf("expected ')' in plural expression")
+		}
+		return fn, nil
+	}
+
+	if _, ok := p.next("n"); ok {
+		return func(n int) int { return n }, nil
+	}
+
+	if p.pos < len(p.tokens) {
+		if value, err := strconv.Atoi(p.tokens[p.pos]); err == nil {
+			p.pos++
+			return func(int) int { return value }, nil
+		}
+		return nil, fmt.Errorf("unexpected plural expression token %q", p.tokens[p.pos])
+	}
+
+	return nil, fmt.Errorf("unexpected end of plural expression")
+}
+
+// sampleNumbers returns sample numbers of plural forms, numbers greater than 1 are preferred.
+// A number is -1 if the form is not used for numbers up to maxSample.
+func (form pluralForm) sampleNumbers(forms int) []int {
+	numbers := make([]int, forms)
+	for i := range numbers {
+		numbers[i] = -1
+	}
+
+	set := func(n int) {
+		if i := form(n); i >= 0 && i < forms && numbers[i] < 0 {
+			numbers[i] = n
+		}
+	}
+
+	for n := 2; n <= maxSample; n++ {
+		set(n)
+	}
+	set(0)
+	set(1)
+
+	return numbers
+}
+
+// sample is a plural message rendered with a number of one plural form, like "5 files" for "%d files".
+type sample struct {
+	text   string
+	number string
+	verb   string
+}
+
+// newSample returns a plural message with the first number format verb replaced by the number.
+// The result is false if there is no such verb or the message has other numbers.
+func newSample(n int, plural string) (sample, bool) {
+	loc := verbRegexp.FindStringIndex(plural)
+	if n < 0 || loc == nil {
+		return sample{}, false
+	}
+
+	if numberRegexp.MatchString(plural[:loc[0]] + plural[loc[1]:]) {
+		return sample{}, false // other numbers of the message can be mixed up with the sample
+	}
+
+	number := strconv.Itoa(n)
+	return sample{text: plural[:loc[0]] + number + plural[loc[1]:], number: number, verb: plural[loc[0]:loc[1]]}, true
+}
+
+// restore returns a translation of the sample with the number replaced back by the format verb,
+// the result is false if the translation doesn't have exactly one such number.
+func (s sample) restore(translation string) (string, bool) {
+	var found [][]int
+
+	for _, loc := range numberRegexp.FindAllStringIndex(translation, -1) {
+		if translation[loc[0]:loc[1]] == s.number {
+			found = append(found, loc)
+		}
+	}
+
+	if len(found) != 1 {
+		return "", false
+	}
+
+	return translation[:found[0][0]] + s.verb + translation[found[0][1]:], true
+}
@@ -0,0 +1,215 @@
+package handle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// LanguagesCommand is a name of supported languages command.
+const LanguagesCommand = "languages"
+
+// Output formats of languages command.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCodes = "codes"
+)
+
+// Kinds of supported languages lists.
+const (
+	kindTranslation = "translation"
+	kindDictionary  = "dictionary"
+	kindSpelling    = "spelling"
+)
+
+// catalog is a filtered list of supported languages.
+type catalog struct {
+	Translation []translation.Language `json:"translation,omitempty"`
+	Dictionary  dictionary.Languages   `json:"dictionary,omitempty"`
+	Spelling    []string               `json:"spelling,omitempty"`
+}
+
+// catalogQuery is a parsed languages command: list kind, search text and dictionary pairs filters.
+type catalogQuery struct {
+	kind   string
+	search string
+	from   string
+	to     string
+}
+
+// parseCatalogQuery parses parameters like "dictionary from:en to:ru" or "translation german".
+func parseCatalogQuery(params []string) *catalogQuery {
+	var (
+		q     = &catalogQuery{}
+		words []string
+	)
+
+	if len(params) > 0 && slices.Contains([]string{kindTranslation, kindDictionary, kindSpelling}, params[0]) {
+		q.kind, params = params[0], params[1:]
+	}
+
+	for _, param := range params {
+		switch {
+		case strings.HasPrefix(param, "from:"):
+			q.from = langtag.Base(strings.TrimPrefix(param, "from:"))
+		case strings.HasPrefix(param, "to:"):
+			q.to = langtag.Base(strings.TrimPrefix(param, "to:"))
+		default:
+			words = append(words, param)
+		}
+	}
+
+	q.search = strings.ToLower(strings.TrimSpace(strings.Join(words, " ")))
+	return q
+}
+
+// has returns true if the list kind should be shown.
+func (q *catalogQuery) has(kind string) bool {
+	return q.kind == "" || q.kind == kind
+}
+
+// matchLanguage returns true if the language code or name contains the search text.
+func (q *catalogQuery) matchLanguage(code, name string) bool {
+	return q.search == "" || strings.Contains(strings.ToLower(code), q.search) ||
+		strings.Contains(strings.ToLower(name), q.search)
+}
+
+// matchPair returns true if the dictionary pair satisfies source and target filters
+// and contains the search text or one of found translation languages.
+func (q *catalogQuery) matchPair(pair string, codes []string) bool {
+	from, to, _ := strings.Cut(pair, "-")
+	if (q.from != "" && from != q.from) || (q.to != "" && to != q.to) {
+		return false
+	}
+
+	return q.search == "" || strings.Contains(pair, q.search) ||
+		slices.Contains(codes, from) || slices.Contains(codes, to)
+}
+
+// RunLanguages prints supported translation languages, dictionary pairs and spelling check languages.
+// Params are an optional list kind ("translation", "dictionary" or "spelling"),
+// dictionary filters "from:<code>" and "to:<code>" and a search text for codes and names.
+func (y *Handler) RunLanguages(ctx context.Context, params []string) error {
+	output, err := y.languagesOutput(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(output)
+	return nil
+}
+
+// languagesOutput returns supported languages in the output format.
+func (y *Handler) languagesOutput(ctx context.Context, params []string) (string, error) {
+	format := y.options.Format
+	if format == "" {
+		format = FormatTable
+	}
+
+	if !slices.Contains([]string{FormatTable, FormatJSON, FormatCodes}, format) {
+		return "", fmt.Errorf("unknown languages output format %q, expected: table, json or codes", format)
+	}
+
+	c, err := y.catalog(ctx, parseCatalogQuery(params))
+	if err != nil {
+		return "", err
+	}
+
+	if len(c.Translation)+len(c.Dictionary)+len(c.Spelling) == 0 {
+		return "", fmt.Errorf("no languages found")
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("encode languages: %w", err)
+		}
+		return string(data), nil
+	case FormatCodes:
+		codes := make([]string, 0, len(c.Translation)+len(c.Dictionary)+len(c.Spelling))
+		for _, lg := range c.Translation {
+			codes = append(codes, lg.Code)
+		}
+		codes = append(append(codes, c.Dictionary...), c.Spelling...)
+		return strings.Join(codes, "\n"), nil
+	}
+
+	return c.table(), nil
+}
+
+// catalog loads languages lists and filters them by the query,
+// translation languages are used to search dictionary pairs and spelling languages by languages names.
+func (y *Handler) catalog(ctx context.Context, q *catalogQuery) (*catalog, error) {
+	var (
+		c     = &catalog{}
+		codes []string
+	)
+
+	// searched languages names are matched by codes of dictionary pairs and spelling languages
+	if q.has(kindTranslation) || q.search != "" {
+		languages, err := y.translationLanguages(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, lg := range languages.Languages {
+			if q.matchLanguage(lg.Code, lg.Name) {
+				codes = append(codes, strings.ToLower(lg.Code))
+				if q.has(kindTranslation) {
+					c.Translation = append(c.Translation, lg)
+				}
+			}
+		}
+	}
+
+	if q.has(kindDictionary) {
+		pairs, err := y.dictionaryLanguages(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pair := range *pairs {
+			if q.matchPair(pair, codes) {
+				c.Dictionary = append(c.Dictionary, pair)
+			}
+		}
+	}
+
+	if q.has(kindSpelling) {
+		for _, code := range y.speller.Languages() {
+			if q.search == "" || strings.Contains(code, q.search) || slices.Contains(codes, code) {
+				c.Spelling = append(c.Spelling, code)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// table returns not empty lists as text tables.
+func (c *catalog) table() string {
+	var sections []string
+
+	if len(c.Translation) > 0 {
+		languages := translation.NewLanguages(c.Translation)
+		sections = append(sections, fmt.Sprintf("Translation languages (%d):\n%s", languages.Len(), languages.Description()))
+	}
+
+	if len(c.Dictionary) > 0 {
+		sections = append(sections, fmt.Sprintf("Dictionary languages (%d):\n%s", c.Dictionary.Len(), c.Dictionary.Table()))
+	}
+
+	if len(c.Spelling) > 0 {
+		sections = append(sections, fmt.Sprintf("Spelling languages (%d):\n%s", len(c.Spelling), strings.Join(c.Spelling, ", ")))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
@@ -0,0 +1,100 @@
+package handle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+func TestHandler_languagesOutput(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Dictionary:  "dict_key",
+		Logger:      logger,
+		URL: map[string]string{
+			dictionary.LanguagesURL:  s.URL + "/api/v1/dicservice.json/getLangs",
+			translation.LanguagesURL: s.URL + "/translate/v2/languages",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		format   string
+		params   []string
+		expected string
+		err      string
+	}{
+		{
+			name: "all",
+			expected: "Translation languages (2):\n" +
+				"ru - Russian              en - English             \n\n" +
+				"Dictionary languages (2):\nen -> en, ru\n\n" +
+				"Spelling languages (3):\nen, ru, uk",
+		},
+		{
+			name:     "search_name",
+			params:   []string{"russ"},
+			expected: "Translation languages (1):\nru - Russian             \n\nDictionary languages (1):\nen -> ru\n\nSpelling languages (1):\nru",
+		},
+		{
+			name:     "dictionary_name",
+			params:   []string{"dictionary", "Russian"},
+			expected: "Dictionary languages (1):\nen -> ru",
+		},
+		{
+			name:     "dictionary_target",
+			format:   FormatCodes,
+			params:   []string{"dictionary", "to:EN"},
+			expected: "en-en",
+		},
+		{
+			name:     "dictionary_source",
+			format:   FormatCodes,
+			params:   []string{"dictionary", "from:en-GB"},
+			expected: "en-en\nen-ru",
+		},
+		{name: "codes", format: FormatCodes, params: []string{"en"}, expected: "en\nen-en\nen-ru\nen"},
+		{name: "spelling", format: FormatCodes, params: []string{"spelling"}, expected: "en\nru\nuk"},
+		{name: "spelling_name", format: FormatCodes, params: []string{"spelling", "Russian"}, expected: "ru"},
+		{
+			name:     "json",
+			format:   FormatJSON,
+			params:   []string{"translation", "EN"},
+			expected: "{\n  \"translation\": [\n    {\n      \"code\": \"en\",\n      \"name\": \"English\"\n    }\n  ]\n}",
+		},
+		{name: "not_found", params: []string{"german"}, err: "no languages found"},
+		{name: "bad_format", format: "xml", err: `unknown languages output format "xml", expected: table, json or codes`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(cfg, Options{Format: tc.format})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := h.languagesOutput(context.Background(), tc.params)
+			if err != nil {
+				if e := err.Error(); e != tc.err {
+					t.Errorf("expected error %q, got %q", tc.err, e)
+				}
+				return
+			}
+
+			if tc.err != "" {
+				t.Fatalf("expected error %q", tc.err)
+			}
+
+			if output != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, output)
+			}
+		})
+	}
+}
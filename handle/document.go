@@ -0,0 +1,227 @@
+package handle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/bundle"
+	"github.com/z0rr0/ytapigo/gettext"
+	"github.com/z0rr0/ytapigo/markdown"
+	"github.com/z0rr0/ytapigo/subtitle"
+	"github.com/z0rr0/ytapigo/translation"
+	"github.com/z0rr0/ytapigo/xliff"
+)
+
+// detectionSampleLength is a max length of a document part which is used for language detection.
+const detectionSampleLength = 1000
+
+// document is a translator of some file format.
+type document struct {
+	format    string // text format for translation API
+	protect   bool   // protect placeholders of plain texts
+	memory    bool   // use translation memory, texts are short messages
+	translate func(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error)
+	finish    func() error // optional, it is called after the result is written
+}
+
+// document returns a translator for the file extension, unknown files are translated as plain text.
+// Bundles written to output file are translated incrementally using a manifest next to the file.
+func (y *Handler) document(ext, output string) (document, error) {
+	switch strings.ToLower(ext) {
+	case ".md", ".markdown":
+		return document{format: translation.FormatHTML, translate: markdown.Translate}, nil
+	case ".srt", ".vtt":
+		subtitles := func(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error) {
+			return subtitle.Translate(ctx, data, y.options.LineWidth, fn)
+		}
+		return document{format: translation.FormatHTML, translate: subtitles}, nil
+	case ".po", ".pot":
+		catalog := func(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error) {
+			return gettext.Translate(ctx, data, y.options.Force, fn)
+		}
+		return document{format: translation.FormatPlain, protect: true, memory: true, translate: catalog}, nil
+	case ".xlf", ".xliff":
+		units := func(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error) {
+			return xliff.Translate(ctx, data, y.toLanguage, fn)
+		}
+		return document{format: translation.FormatHTML, translate: units}, nil
+	case ".json", ".yaml", ".yml":
+		options := &bundle.Options{
+			Source:  y.fromLanguage,
+			Target:  y.toLanguage,
+			Include: y.options.Include,
+			Exclude: y.options.Exclude,
+		}
+		resources := func(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error) {
+			return bundle.Translate(ctx, data, ext, options, fn)
+		}
+
+		doc := document{format: translation.FormatPlain, protect: true, memory: true, translate: resources}
+		if output == "" {
+			return doc, nil
+		}
+
+		if err := y.incremental(options, output); err != nil {
+			return document{}, err
+		}
+
+		manifest := bundle.ManifestName(output)
+		doc.finish = func() error {
+			return options.Manifest.Save(manifest)
+		}
+		return doc, nil
+	default:
+		return document{format: translation.FormatPlain, protect: y.options.Protect, translate: plainText}, nil
+	}
+}
+
+// incremental loads previous output and its manifest to bundle options.
+// Previous output is ignored if force flag is set, so all values are translated again.
+func (y *Handler) incremental(options *bundle.Options, output string) error {
+	manifest, err := bundle.LoadManifest(bundle.ManifestName(output))
+	if err != nil {
+		return err
+	}
+
+	options.Manifest = manifest
+	if y.options.Force {
+		return nil
+	}
+
+	options.Previous, err = os.ReadFile(filepath.Clean(output))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read previous output: %w", err)
+	}
+
+	return nil
+}
+
+// plainText translates data as one plain text.
+func plainText(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error) {
+	texts, err := fn(ctx, []string{string(data)})
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(texts[0]), nil
+}
+
+// sample returns the beginning of text for language detection.
+func sample(text string) string {
+	runes := []rune(text)
+	if len(runes) <= detectionSampleLength {
+		return text
+	}
+
+	return string(runes[:detectionSampleLength])
+}
+
+// batch returns a function which translates texts of the document format.
+// Placeholders of plain texts are protected, so they are translated as HTML.
+// Messages are looked up in translation memory before translation.
+func (y *Handler) batch(doc document) (translation.BatchFunc, error) {
+	fn := y.request(doc.format)
+
+	if doc.protect && doc.format == translation.FormatPlain {
+		p, err := y.protector()
+		if err != nil {
+			return nil, err
+		}
+		fn = p.Wrap(y.request(translation.FormatHTML))
+	}
+
+	if !doc.memory {
+		return fn, nil
+	}
+
+	return y.remember(fn)
+}
+
+// request returns a function which translates texts of the format.
+func (y *Handler) request(format string) translation.BatchFunc {
+	return func(ctx context.Context, texts []string) ([]string, error) {
+		request := &translation.Request{
+			Texts:              texts,
+			SourceLanguageCode: y.fromLanguage,
+			TargetLanguageCode: y.toLanguage,
+			Format:             format,
+		}
+
+		response, err := y.translator.Translate(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]string, len(response.Translations))
+		for i, item := range response.Translations {
+			result[i] = item.Text
+		}
+
+		return result, nil
+	}
+}
+
+// RunFile translates a file keeping its structure, the translator is chosen by file extension.
+// Plain text files of any length keep their whitespace and paragraph breaks.
+// The result is written to output file or stdout if output is empty.
+func (y *Handler) RunFile(ctx context.Context, direction, input, output string) error {
+	data, err := os.ReadFile(filepath.Clean(input))
+	if err != nil {
+		return fmt.Errorf("read input file: %w", err)
+	}
+
+	y.text, y.isDictionary = sample(string(data)), false
+	if err = y.setLanguages(ctx, direction); err != nil {
+		return err
+	}
+
+	if len(y.targets) > 1 {
+		return fmt.Errorf("several target languages are not supported for files")
+	}
+
+	doc, err := y.document(filepath.Ext(input), output)
+	if err != nil {
+		return err
+	}
+
+	fn, err := y.batch(doc)
+	if err != nil {
+		return err
+	}
+
+	result, err := doc.translate(ctx, data, fn)
+	if err != nil {
+		return fmt.Errorf("translate file %q: %w", input, err)
+	}
+
+	if err = writeOutput(output, result); err != nil {
+		return err
+	}
+
+	if doc.finish != nil {
+		if err = doc.finish(); err != nil {
+			return err
+		}
+	}
+
+	return y.saveMemory()
+}
+
+// writeOutput writes data to a file or stdout if fileName is empty.
+func writeOutput(fileName string, data []byte) error {
+	if fileName == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Clean(fileName), data, 0600); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+
+	return nil
+}
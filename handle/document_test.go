@@ -0,0 +1,68 @@
+package handle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+func TestHandler_RunFile(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Logger:      logger,
+		URL:         map[string]string{translation.URL: s.URL + "/translate/v2/translate"},
+	}
+
+	h, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "input.txt"), filepath.Join(dir, "output.txt")
+
+	if err := os.WriteFile(input, []byte("\ntime to start\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.RunFile(context.Background(), "en-ru", input, output); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "\nпора начинать\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, string(data))
+	}
+
+	if err = h.RunFile(context.Background(), "en-ru", input+".not-exists", output); err == nil {
+		t.Error("expected error")
+	}
+
+	if err = h.RunFile(context.Background(), "en-ru,uk", input, output); err == nil {
+		t.Error("expected error for several target languages")
+	}
+
+	// test server response doesn't contain the placeholder
+	if err = os.WriteFile(input, []byte("time to start, {name}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h.options.Protect = true
+	err = h.RunFile(context.Background(), "en-ru", input, output)
+	if err == nil || !strings.Contains(err.Error(), `lost protected element "{name}"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package handle
+
+import (
+	"context"
+
+	"github.com/z0rr0/ytapigo/explain"
+	"github.com/z0rr0/ytapigo/result"
+)
+
+// explanation translates the text to the target language and glosses its content words by dictionary lookups.
+// Glosses are skipped if the dictionary doesn't support the language pair.
+func (y *Handler) explanation(ctx context.Context, toLanguage string) (result.Translation, error) {
+	forward, err := y.translation(ctx, toLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	explained := &explain.Result{Translation: forward.String()}
+
+	languages, err := y.dictionaryLanguages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !languages.Contains(y.fromLanguage, toLanguage) {
+		y.config.Logger.Printf("no dictionary for %s-%s, words are not explained", y.fromLanguage, toLanguage)
+		return explained, nil
+	}
+
+	if explained.Glosses, err = y.glosses(ctx, explain.Words(y.text, y.fromLanguage), toLanguage); err != nil {
+		return nil, err
+	}
+
+	return explained, nil
+}
+
+// glosses returns glosses of the words by concurrent dictionary lookups,
+// words without dictionary articles or with failed lookups are skipped, glosses keep the order of the words.
+func (y *Handler) glosses(ctx context.Context, words []string, toLanguage string) ([]explain.Gloss, error) {
+	responses, err := y.lookups(ctx, words, toLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	glosses := make([]explain.Gloss, 0, len(words))
+	for i, response := range responses {
+		if response == nil {
+			continue // failed lookup
+		}
+
+		if gloss, ok := explain.NewGloss(words[i], response); ok {
+			glosses = append(glosses, gloss)
+		}
+	}
+
+	return glosses, nil
+}
@@ -0,0 +1,100 @@
+package handle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/explain"
+	"github.com/z0rr0/ytapigo/provider"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// failedDictionary fails lookups of one word.
+type failedDictionary struct {
+	provider.Dictionary
+	word string
+}
+
+func (d failedDictionary) Lookup(ctx context.Context, r *dictionary.Request) (*dictionary.Response, error) {
+	if r.Text == d.word {
+		return nil, errors.New("lookup failed")
+	}
+	return d.Dictionary.Lookup(ctx, r)
+}
+
+func TestHandler_explanation(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Logger:      logger,
+		URL: map[string]string{
+			translation.URL:           s.URL + "/translate/v2/translate",
+			spelling.URL:              s.URL + "/services/spellservice.json/checkText",
+			dictionary.TranslationURL: s.URL + "/api/v1/dicservice.json/lookup",
+			dictionary.LanguagesURL:   s.URL + "/api/v1/dicservice.json/getLangs",
+		},
+	}
+
+	h, err := New(cfg, Options{Explain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one word is translated by translation API too
+	if err = h.Run(context.Background(), "en-ru", []string{"time"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if h.isDictionary {
+		t.Error("dictionary mode is not expected")
+	}
+
+	h.text = "It is time to start, the time is now"
+	r, err := h.explanation(context.Background(), Ru)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, ok := r.(*explain.Result)
+	if !ok {
+		t.Fatalf("unexpected result type %T", r)
+	}
+
+	// test server returns the same article for every word
+	expected := []explain.Gloss{
+		{Word: "time", Ts: "taɪm", Translation: "время"},
+		{Word: "start", Ts: "taɪm", Translation: "время"},
+		{Word: "now", Ts: "taɪm", Translation: "время"},
+	}
+	if item.Translation != "пора начинать" || !slices.Equal(item.Glosses, expected) {
+		t.Errorf("unexpected result %+v", item)
+	}
+
+	// failed lookup of one word doesn't break the translation
+	h.dictionary = failedDictionary{Dictionary: h.dictionary, word: "start"}
+	if r, err = h.explanation(context.Background(), Ru); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = slices.Delete(expected, 1, 2)
+	if item = r.(*explain.Result); item.Translation != "пора начинать" || !slices.Equal(item.Glosses, expected) {
+		t.Errorf("unexpected result %+v", item)
+	}
+
+	// en-de is not a dictionary pair
+	if r, err = h.explanation(context.Background(), "de"); err != nil {
+		t.Fatal(err)
+	}
+
+	if item = r.(*explain.Result); len(item.Glosses) != 0 {
+		t.Errorf("unexpected glosses %v", item.Glosses)
+	}
+}
@@ -1,39 +1,114 @@
 package handle
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/z0rr0/ytapigo/arguments"
 	"github.com/z0rr0/ytapigo/config"
 	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/health"
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/memory"
+	"github.com/z0rr0/ytapigo/placeholder"
+	"github.com/z0rr0/ytapigo/provider"
 	"github.com/z0rr0/ytapigo/result"
-	"github.com/z0rr0/ytapigo/spelling"
 	"github.com/z0rr0/ytapigo/translation"
 )
 
+// Modes of text requests.
+const (
+	ModeAuto       = ""           // dictionary lookup of one word, translation of other texts
+	ModeDictionary = "dictionary" // dictionary lookup of any text, like idioms
+	ModeTranslate  = "translate"  // translation of any text, like single words
+)
+
+// lookupRequests is a max number of concurrent dictionary lookups of separate words.
+const lookupRequests = 4
+
+// Options are additional parameters of the handler.
+type Options struct {
+	LineWidth int             // max line length of translated subtitles, 0 - no wrapping
+	Force     bool            // translate messages which already have translations
+	Include   []string        // key path patterns of translated resource bundle values
+	Exclude   []string        // key path patterns of skipped resource bundle values
+	Protect   bool            // protect placeholders of plain texts and files
+	Back      bool            // translate texts back to the source language to check translation quality
+	Explain   bool            // gloss content words of translated texts by dictionary lookups
+	Reverse   bool            // look up synonyms of every synonym found by thesaurus command
+	Format    string          // output format of languages command: table, json or codes
+	UI        string          // dictionary language of parts of speech names and notes
+	Flags     []string        // dictionary lookup flags names
+	View      dictionary.View // dictionary articles output options
+	Mode      string          // dictionary or translation mode, it depends on words count if empty
+	Name      string          // application name
+	Version   string          // application version
+}
+
 // Handler is a common meta-data storage for translation and spelling check requests.
 type Handler struct {
 	config       *config.Config
-	client       *http.Client
+	options      Options
+	translator   provider.Translator
+	dictionary   provider.Dictionary
+	speller      provider.SpellChecker
 	isDictionary bool
 	text         string
 	fromLanguage string
 	toLanguage   string
+	targets      []string // all target languages, the first one is toLanguage
+	memory       *memory.Memory
+	memoryOnce   sync.Once
+	memoryErr    error
 }
 
-// New creates a new handler.
-func New(cfg *config.Config) *Handler {
-	return &Handler{
-		config: cfg,
-		client: &http.Client{Transport: &http.Transport{Proxy: cfg.Proxy}},
+// New creates a new handler with translation, dictionary and spelling check providers selected in configuration.
+func New(cfg *config.Config, options Options) (*Handler, error) {
+	var (
+		y      = &Handler{config: cfg, options: options}
+		client = &http.Client{Transport: &http.Transport{Proxy: cfg.Proxy}}
+	)
+
+	tracker, err := health.New(cfg.HealthCache, cfg.CooldownPeriod(), cfg.Refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if y.translator, err = provider.NewTranslatorChain(cfg.Providers.Translation, client, cfg, tracker); err != nil {
+		return nil, err
+	}
+
+	if y.dictionary, err = provider.NewDictionaryChain(cfg.Providers.Dictionary, client, cfg, tracker); err != nil {
+		return nil, err
+	}
+
+	if y.speller, err = provider.NewSpellCheckerChain(cfg.Providers.Spelling, client, cfg, tracker); err != nil {
+		return nil, err
 	}
+
+	return y, nil
 }
 
 // Run runs translation, spelling check and prints their results.
 func (y *Handler) Run(ctx context.Context, direction string, params []string) error {
 	y.text, y.isDictionary = arguments.TextWithDictionary(params)
+	switch {
+	case y.options.Back:
+		y.isDictionary = false // round-trip translation compares texts
+	case y.options.Explain:
+		y.isDictionary = false // words of translated texts are looked up separately
+	case y.options.Mode == ModeDictionary:
+		y.isDictionary = y.text != ""
+	case y.options.Mode == ModeTranslate:
+		y.isDictionary = false
+	case y.dictionary.Name() == provider.None:
+		y.isDictionary = false // dictionary is disabled, words are translated
+	}
 
 	err := y.setLanguages(ctx, direction)
 	if err != nil {
@@ -46,80 +121,278 @@ func (y *Handler) Run(ctx context.Context, direction string, params []string) er
 		return err
 	}
 
-	result.Show(results)
-	return nil
-}
-
-// loadLanguages loads languages defined by dictionary or translation API will be used.
-func (y *Handler) loadLanguages(ctx context.Context) (result.Languages, error) {
-	if y.isDictionary {
-		return dictionary.LoadLanguages(ctx, y.client, y.config)
+	suggestions, err := y.suggestions()
+	if err != nil {
+		return err
 	}
-	return translation.LoadLanguages(ctx, y.client, y.config)
+
+	result.Show(append(results, suggestions))
+	return y.saveMemory()
 }
 
-// setLanguages detects language direction.
+// setLanguages detects language direction, several target languages can be separated by commas.
+// Languages names and close matches are resolved by one loaded languages list.
 func (y *Handler) setLanguages(ctx context.Context, direction string) error {
+	direction, extraTargets := splitTargets(direction)
+
 	fromLanguage, toLanguage, err := y.detectLanguages(ctx, direction, y.text)
 	if err != nil {
 		return err
 	}
 
-	if knowLanguages(fromLanguage, toLanguage) {
-		y.fromLanguage, y.toLanguage = fromLanguage, toLanguage
-		return nil
-	}
-
-	languages, err := y.loadLanguages(ctx)
-	if err != nil {
-		return fmt.Errorf("can not set languages: %w", err)
+	targets := []string{toLanguage}
+	for _, target := range extraTargets {
+		if target, err = normalizeLanguage(target); err != nil {
+			return err
+		}
+		targets = append(targets, target)
 	}
 
-	if !languages.Contains(fromLanguage, toLanguage) {
-		return fmt.Errorf("unknown language direction: %s -> %s\n%v", fromLanguage, toLanguage, languages.String())
+	if !knowLanguages(append([]string{fromLanguage}, targets...)...) {
+		if fromLanguage, targets, err = y.resolveLanguages(ctx, fromLanguage, targets); err != nil {
+			return err
+		}
 	}
 
-	y.fromLanguage, y.toLanguage = fromLanguage, toLanguage
+	// names and codes can be resolved to the same languages
+	targets = distinct(targets)
+	y.fromLanguage, y.toLanguage, y.targets = fromLanguage, targets[0], targets
 	return nil
 }
 
-// translationAndSpelling runs translation and spelling check requests concurrently.
+// translationAndSpelling runs spelling check and translation requests for every target language concurrently.
+// Translations are labeled by target languages if there are several ones
+// and by names of providers which answered if fallback chains are used.
 func (y *Handler) translationAndSpelling(ctx context.Context) ([]result.Translation, error) {
-	const handlersCount = 2
+	handlersCount := len(y.targets)
 
 	ch := make(chan result.Item, 1)
 	defer close(ch)
 
-	go func() {
-		t, e := spelling.Request(ctx, y.client, y.fromLanguage, y.text, y.config)
-		ch <- result.Item{Translation: t, Priority: 1, Err: e}
-	}()
+	// local dictionaries lookups don't require network
+	if !y.isDictionary || !provider.HasOffline(y.dictionary) {
+		handlersCount++
+		go func() {
+			tracedCtx, answered := traced(ctx)
+			t, e := y.speller.Check(tracedCtx, y.fromLanguage, y.text)
+			ch <- result.Item{Translation: labeled(t, answered()), Priority: 1, Err: e}
+		}()
+	}
 
-	go func() {
-		t, e := y.translation(ctx)
-		ch <- result.Item{Translation: t, Priority: 2, Err: e}
-	}()
+	translate := y.translation
+	switch {
+	case y.options.Back:
+		translate = y.roundTrip
+	case y.options.Explain:
+		translate = y.explanation
+	}
+
+	for i, target := range y.targets {
+		go func() {
+			tracedCtx, answered := traced(ctx)
+			t, e := translate(tracedCtx, target)
+			if e == nil && len(y.targets) > 1 {
+				t = labeled(t, target, answered())
+			} else if e == nil {
+				t = labeled(t, answered())
+			}
+			ch <- result.Item{Translation: t, Priority: uint8(2 + i), Err: e}
+		}()
+	}
 
 	return result.Build(ch, handlersCount)
 }
 
-// translation does translation API request.
-func (y *Handler) translation(ctx context.Context) (result.Translation, error) {
+// traced returns a context which saves a name of the provider chain item which answered
+// and a function to get this name, it is empty if there was no providers chain.
+func traced(ctx context.Context) (context.Context, func() string) {
+	var (
+		mu   sync.Mutex
+		name string
+	)
+
+	trace := &provider.Trace{Answered: func(_, item string) {
+		mu.Lock()
+		defer mu.Unlock()
+		name = item
+	}}
+
+	return provider.WithTrace(ctx, trace), func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return name
+	}
+}
+
+// labeled returns the translation with a label of not empty parts.
+func labeled(t result.Translation, parts ...string) result.Translation {
+	parts = slices.DeleteFunc(parts, func(part string) bool { return part == "" })
+	if len(parts) == 0 {
+		return t
+	}
+	return &result.Labeled{Label: strings.Join(parts, ", "), Translation: t}
+}
+
+// translation does translation API request to the target language.
+// In auto mode a text without dictionary articles is translated by translation API.
+func (y *Handler) translation(ctx context.Context, toLanguage string) (result.Translation, error) {
 	if y.isDictionary {
-		request := &dictionary.Request{
-			Key:                y.config.Dictionary,
-			Text:               y.text,
-			TargetLanguageCode: y.toLanguage,
-			SourceLanguageCode: y.fromLanguage,
+		request, err := y.dictionaryRequest(y.text, toLanguage)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := y.dictionary.Lookup(ctx, request)
+		if err != nil {
+			return nil, err
 		}
-		return dictionary.Translate(ctx, y.client, y.config, request)
+
+		if len(response.Def) > 0 || y.options.Mode == ModeDictionary {
+			return response.Apply(&y.options.View), nil
+		}
+
+		y.config.Logger.Printf("no dictionary articles for %q, it is translated", y.text)
 	}
 
-	request := &translation.Request{
-		FolderID:           y.config.Translation.FolderID,
-		Texts:              []string{y.text},
+	tm, err := y.translationMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	if tm != nil {
+		if e, ok := tm.Get(y.text, y.fromLanguage, toLanguage); ok {
+			y.config.Logger.Printf("translation memory exact match %s", e.ID())
+			return &translation.Response{Translations: []translation.ResponseItem{{Text: e.Target}}}, nil
+		}
+	}
+
+	response, err := y.translateText(ctx, y.text, y.fromLanguage, toLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	if tm != nil {
+		tm.Add(y.text, response.String(), y.fromLanguage, toLanguage)
+	}
+
+	return response, nil
+}
+
+// dictionaryRequest returns a dictionary request with lookup options of the language pair,
+// command line options override configuration ones.
+func (y *Handler) dictionaryRequest(text, toLanguage string) (*dictionary.Request, error) {
+	var (
+		lookup = y.lookupDefaults(toLanguage)
+		ui     = cmp.Or(y.options.UI, lookup.UI)
+		names  = lookup.Flags
+	)
+
+	if len(y.options.Flags) > 0 {
+		names = y.options.Flags
+	}
+
+	flags, err := dictionary.ParseFlags(names)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &dictionary.Request{
+		Text:               text,
+		TargetLanguageCode: toLanguage,
 		SourceLanguageCode: y.fromLanguage,
-		TargetLanguageCode: y.toLanguage,
+		UI:                 ui,
+		Flags:              flags,
+	}
+	return request, nil
+}
+
+// lookups does concurrent dictionary lookups of the words, not more than lookupRequests at once.
+// Responses keep the order of the words, failed lookups are logged and their responses are nil,
+// so one unknown word doesn't break results of other ones.
+func (y *Handler) lookups(ctx context.Context, words []string, toLanguage string) ([]*dictionary.Response, error) {
+	var (
+		wg        sync.WaitGroup
+		responses = make([]*dictionary.Response, len(words))
+		semaphore = make(chan struct{}, lookupRequests)
+	)
+
+	for i, word := range words {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			request, err := y.dictionaryRequest(word, toLanguage)
+			if err == nil {
+				responses[i], err = y.dictionary.Lookup(ctx, request)
+			}
+
+			if err != nil {
+				y.config.Logger.Printf("dictionary lookup of %q failed: %v", word, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	// a timeout or cancellation is not an error of separate words
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// lookupDefaults returns configuration lookup options of the dictionary language pair.
+func (y *Handler) lookupDefaults(toLanguage string) config.DictionaryOptions {
+	pair := langtag.Base(y.fromLanguage) + "-" + langtag.Base(toLanguage)
+
+	for key, lookup := range y.config.Lookup {
+		if strings.EqualFold(key, pair) {
+			return lookup
+		}
+	}
+
+	return config.DictionaryOptions{}
+}
+
+// translateText translates a text by translation API, its placeholders are protected if it's required.
+func (y *Handler) translateText(ctx context.Context, text, fromLanguage, toLanguage string) (*translation.Response, error) {
+	request := &translation.Request{
+		Texts:              []string{text},
+		SourceLanguageCode: fromLanguage,
+		TargetLanguageCode: toLanguage,
+	}
+
+	if !y.options.Protect {
+		return y.translator.Translate(ctx, request)
+	}
+
+	p, err := y.protector()
+	if err != nil {
+		return nil, err
+	}
+
+	masked, tags := p.Mask(text)
+	request.Texts, request.Format = []string{masked}, translation.FormatHTML
+
+	response, err := y.translator.Translate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response.Translations {
+		if response.Translations[i].Text, err = placeholder.Restore(response.Translations[i].Text, tags); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// protector returns a placeholders protector with user defined patterns from configuration.
+func (y *Handler) protector() (*placeholder.Protector, error) {
+	p, err := placeholder.New(y.config.Placeholders)
+	if err != nil {
+		return nil, fmt.Errorf("placeholders protection: %w", err)
 	}
-	return translation.Translate(ctx, y.client, y.config, request)
+	return p, nil
 }
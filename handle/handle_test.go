@@ -96,8 +96,10 @@ func TestHandler_Run(t *testing.T) {
 		},
 	}
 
-	h := New(cfg)
-	h.client = s.Client()
+	h, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	testCases := []struct {
 		name      string
@@ -117,14 +119,49 @@ func TestHandler_Run(t *testing.T) {
 			name:      "dictionary_err",
 			direction: "de-fr",
 			params:    []string{"time"},
-			error:     "unknown language direction: de -> fr\nDictionary languages:\nen-en, en-ru",
+			error:     "unknown language direction: de-fr",
+		},
+		{name: "dictionary_names", direction: "English-RUSSIAN", params: []string{"time"}},
+		{
+			name:      "dictionary_pair_err",
+			direction: "russian-english",
+			params:    []string{"time"},
+			error:     "unknown language direction: ru-en, did you mean en-en?",
+		},
+		{name: "translation_targets", direction: "ru-en,uk, ru,", params: []string{"пора начинать"}},
+		{name: "dictionary_targets", direction: "en-ru,en", params: []string{"time"}},
+		{
+			name:      "translation_targets_err",
+			direction: "en-ru,de",
+			params:    []string{"time to start"},
+			error:     "unknown language direction: en-ru,de",
 		},
 		{
 			name:      "translation_err",
 			direction: "de-fr",
 			params:    []string{"time to start"},
-			error: "unknown language direction: de -> fr\nTranslation languages:\nru, en\n" +
-				"ru - Russian              en - English             ",
+			error:     "unknown language direction: de-fr",
+		},
+		{name: "translation_names", direction: "Englsh-russan,EN", params: []string{"time to start"}},
+		{name: "translation_tags", direction: "en-GB:ru-RU", params: []string{"time to start"}},
+		{name: "dictionary_tags", direction: "en-gb -> ru", params: []string{"time"}},
+		{
+			name:      "translation_tag_err",
+			direction: "en:zh-Hans-Latn",
+			params:    []string{"time to start"},
+			error:     `invalid language tag "zh-Hans-Latn": unexpected subtag "latn"`,
+		},
+		{
+			name:      "translation_tag_unknown",
+			direction: "en->de-AT,ru",
+			params:    []string{"time to start"},
+			error:     "unknown language direction: en:de-AT,ru",
+		},
+		{
+			name:      "translation_suggestion",
+			direction: "en-ry",
+			params:    []string{"time to start"},
+			error:     "unknown language direction: en-ry, did you mean en-ru?",
 		},
 	}
 
@@ -139,3 +176,78 @@ func TestHandler_Run(t *testing.T) {
 		}
 	}
 }
+
+func TestHandler_dictionaryRequest(t *testing.T) {
+	cfg := &config.Config{
+		Logger: logger,
+		Lookup: map[string]config.DictionaryOptions{"EN-RU": {UI: "ru", Flags: []string{"morpho", "family"}}},
+	}
+
+	testCases := []struct {
+		name    string
+		options Options
+		to      string
+		ui      string
+		flags   int
+		err     string
+	}{
+		{name: "defaults", to: "ru", ui: "ru", flags: dictionary.FlagMorpho | dictionary.FlagFamily},
+		{name: "tag", to: "ru-RU", ui: "ru", flags: dictionary.FlagMorpho | dictionary.FlagFamily},
+		{name: "other_pair", to: "de"},
+		{name: "options", options: Options{UI: "en", Flags: []string{"short_pos"}}, to: "ru", ui: "en", flags: dictionary.FlagShortPos},
+		{name: "ui_only", options: Options{UI: "en"}, to: "ru", ui: "en", flags: dictionary.FlagMorpho | dictionary.FlagFamily},
+		{name: "unknown", options: Options{Flags: []string{"all"}}, to: "ru", err: `unknown dictionary flag "all", expected: family, short_pos, morpho or pos_filter`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(cfg, tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.fromLanguage = "en-GB"
+
+			request, err := h.dictionaryRequest("went", tc.to)
+			if err != nil {
+				if e := err.Error(); e != tc.err {
+					t.Errorf("expected error %q, got %q", tc.err, e)
+				}
+				return
+			}
+
+			if tc.err != "" {
+				t.Fatalf("expected error %q", tc.err)
+			}
+
+			if request.UI != tc.ui || request.Flags != tc.flags {
+				t.Errorf("expected ui %q and flags %d, got %q and %d", tc.ui, tc.flags, request.UI, request.Flags)
+			}
+		})
+	}
+}
+
+func TestHandler_translationView(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Logger: logger,
+		URL:    map[string]string{dictionary.TranslationURL: s.URL + "/api/v1/dicservice.json/lookup"},
+	}
+
+	h, err := New(cfg, Options{View: dictionary.View{MaxSyn: 1, NoMeanings: true, Compact: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.text, h.fromLanguage, h.isDictionary = "time", "en", true
+
+	response, err := h.translation(context.Background(), "ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "time [taɪm] (noun)\n\tвремя, раз"
+	if result := response.String(); result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
+	}
+}
@@ -3,11 +3,15 @@ package handle
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"slices"
 	"strings"
+	"unicode/utf8"
 
-	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/langcache"
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/memory"
+	"github.com/z0rr0/ytapigo/provider"
 	"github.com/z0rr0/ytapigo/translation"
 )
 
@@ -23,10 +27,12 @@ const (
 	// AutoLanguageDetect is a constant for auto-detect language using API request.
 	AutoLanguageDetect = "auto"
 
-	// in common case, the max length of direction is 7 symbols
-	// but most used cases have format with 5 ones: "en-ru" and "ru-en"
-	minDirectionLength = 5
-	maxDirectionLength = 7
+	// minPrefixLength is a min length of language name prefix like "germ" to resolve it.
+	minPrefixLength = 3
+	// closeSimilarity is a min similarity of language name with a typo to accept it.
+	closeSimilarity = 0.75
+	// hintSimilarity is a min similarity of unknown language to suggest a close one.
+	hintSimilarity = 0.5
 )
 
 var autoAllowedLanguages = map[string]struct{}{En: {}, Ru: {}, Uk: {}}
@@ -73,8 +79,8 @@ func asciiDetection(text string) (string, string) {
 
 // autoAPIDetection does API detection request.
 // If successful, returns detected language and Russian as a target language.
-func autoAPIDetection(ctx context.Context, client *http.Client, cfg *config.Config, text string) (string, string, error) {
-	fromLanguage, err := translation.DetectLanguage(ctx, client, cfg, text)
+func autoAPIDetection(ctx context.Context, translator provider.Translator, text string) (string, string, error) {
+	fromLanguage, err := translator.Detect(ctx, text)
 	if err != nil {
 		return "", "", fmt.Errorf("auto detect language error: %w", err)
 	}
@@ -82,31 +88,112 @@ func autoAPIDetection(ctx context.Context, client *http.Client, cfg *config.Conf
 	return fromLanguage, Ru, nil
 }
 
+// directionSeparators are separators of source and target languages,
+// they allow BCP-47 tags with hyphens like "en:zh-Hans" or "en->sr-Latn", hyphen is used for simple "en-ru".
+var directionSeparators = []string{"->", ":", "-"}
+
+// splitDirection splits direction by the first found separator, the flag is false if there is no separator.
+func splitDirection(direction string) (string, string, bool) {
+	for _, separator := range directionSeparators {
+		if fromLanguage, toLanguage, ok := strings.Cut(direction, separator); ok {
+			return fromLanguage, toLanguage, !strings.Contains(toLanguage, separator)
+		}
+	}
+	return "", "", false
+}
+
 // splitDetection splits direction string to two languages: source and target.
+// Languages can be set by codes, BCP-47 tags or names in any case, like "en-ru", "en:zh-Hans" or "English-German".
 func splitDetection(direction string) (string, string, error) {
-	var n = len(direction)
+	fromLanguage, toLanguage, ok := splitDirection(direction)
+	if !ok {
+		return "", "", fmt.Errorf("invalid direction format: %s", direction)
+	}
 
-	if n > maxDirectionLength {
-		return "", "", fmt.Errorf("too long direction format='%s'", direction)
+	fromLanguage, err := normalizeLanguage(fromLanguage)
+	if err != nil {
+		return "", "", err
 	}
 
-	if n < minDirectionLength {
-		return "", "", fmt.Errorf("too short direction format='%s'", direction)
+	toLanguage, err = normalizeLanguage(toLanguage)
+	if err != nil {
+		return "", "", err
 	}
 
-	languages := strings.SplitN(direction, "-", 3)
+	return fromLanguage, toLanguage, nil
+}
+
+// normalizeLanguage returns a language tag in canonical case or a language name in lower case.
+// Values with hyphens must be valid BCP-47 tags.
+func normalizeLanguage(language string) (string, error) {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return "", fmt.Errorf("empty language in direction")
+	}
 
-	if len(languages) != 2 {
-		return "", "", fmt.Errorf("invalid direction format: %s", direction)
+	if !strings.Contains(language, "-") {
+		return langtag.Canonical(language), nil
 	}
 
-	return languages[0], languages[1], nil
+	tag, err := langtag.Parse(language)
+	if err != nil {
+		return "", err
+	}
+
+	return tag.String(), nil
+}
+
+// splitTargets splits direction like "en-ru,de,fr" to a direction with the first target language
+// ("en-ru") and additional target languages.
+func splitTargets(direction string) (string, []string) {
+	direction, extra, ok := strings.Cut(direction, ",")
+	if !ok {
+		return direction, nil
+	}
+
+	var targets []string
+	for target := range strings.SplitSeq(extra, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+
+	return direction, targets
+}
+
+// distinct returns items without duplicates keeping their order.
+func distinct(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if !slices.Contains(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// translationLanguages returns cached or loaded languages of the translation provider.
+func (y *Handler) translationLanguages(ctx context.Context) (*translation.Languages, error) {
+	return langcache.Translation(y.config, y.translator.Name(), func() (*translation.Languages, error) {
+		return y.translator.Languages(ctx)
+	})
+}
+
+// dictionaryLanguages returns cached or loaded language pairs of the dictionary provider.
+func (y *Handler) dictionaryLanguages(ctx context.Context) (*dictionary.Languages, error) {
+	if provider.HasOffline(y.dictionary) || y.dictionary.Name() == provider.None {
+		return y.dictionary.Languages(ctx) // local dictionaries are set in configuration, their pairs are not cached
+	}
+
+	return langcache.Dictionary(y.config, y.dictionary.Name(), func() (*dictionary.Languages, error) {
+		return y.dictionary.Languages(ctx)
+	})
 }
 
 // detectLanguages tries to detect languages for translation and spelling check.
 func (y *Handler) detectLanguages(ctx context.Context, direction, text string) (string, string, error) {
 	if direction == AutoLanguageDetect {
-		return autoAPIDetection(ctx, y.client, y.config, text)
+		return autoAPIDetection(ctx, y.translator, text)
 	}
 
 	if direction == "" {
@@ -116,3 +203,191 @@ func (y *Handler) detectLanguages(ctx context.Context, direction, text string) (
 
 	return splitDetection(direction)
 }
+
+// resolveLanguage returns a code of the language set by code, name, unique name prefix
+// or name with a typo, like "de", "German", "germ" or "germen".
+// BCP-47 tags are looked up from the most specific form to the primary language: "zh-Hans-CN", "zh-Hans", "zh".
+func resolveLanguage(languages []translation.Language, query string) (string, bool) {
+	var prefixed []string
+
+	if tag, err := langtag.Parse(query); err == nil {
+		for _, candidate := range tag.Fallbacks() {
+			for _, lg := range languages {
+				if strings.EqualFold(lg.Code, candidate) {
+					return lg.Code, true
+				}
+			}
+		}
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	for _, lg := range languages {
+		name := strings.ToLower(lg.Name)
+		if query == name {
+			return lg.Code, true
+		}
+
+		if len(query) >= minPrefixLength && strings.HasPrefix(name, query) {
+			prefixed = append(prefixed, lg.Code)
+		}
+	}
+
+	if len(prefixed) == 1 {
+		return prefixed[0], true
+	}
+
+	if utf8.RuneCountInString(query) <= minPrefixLength {
+		return "", false // codes are too short to fix typos
+	}
+
+	code, score, unique := closestLanguage(languages, query)
+	return code, unique && score >= closeSimilarity
+}
+
+// closestLanguage returns a code of the most similar language by its code, name or name prefix,
+// the flag is false if several languages have the same similarity.
+func closestLanguage(languages []translation.Language, query string) (string, float64, bool) {
+	var (
+		code   string
+		best   float64
+		unique bool
+		n      = utf8.RuneCountInString(query)
+	)
+
+	for _, lg := range languages {
+		name := []rune(strings.ToLower(lg.Name))
+		score := max(
+			memory.Similarity(query, strings.ToLower(lg.Code)),
+			memory.Similarity(query, string(name)),
+			memory.Similarity(query, string(name[:min(n, len(name))])),
+		)
+
+		switch {
+		case score > best:
+			code, best, unique = lg.Code, score, true
+		case score == best:
+			unique = false
+		}
+	}
+
+	return code, best, unique
+}
+
+// suggestLanguage returns a code of the close language or an empty string if there is no similar one.
+func suggestLanguage(languages []translation.Language, query string) string {
+	if code, ok := resolveLanguage(languages, query); ok {
+		return code
+	}
+
+	code, score, _ := closestLanguage(languages, strings.ToLower(query))
+	if score < hintSimilarity {
+		return ""
+	}
+	return code
+}
+
+// suggestPair returns the most similar dictionary language pair or an empty string if there is no similar one.
+func suggestPair(languages dictionary.Languages, pair string) string {
+	var (
+		suggestion string
+		best       = hintSimilarity
+	)
+
+	for _, lg := range languages {
+		if score := memory.Similarity(pair, lg); score >= best {
+			if score > best || suggestion == "" {
+				suggestion, best = lg, score
+			}
+		}
+	}
+
+	return suggestion
+}
+
+// directionError returns an error of unknown language direction with a suggested one if it's possible.
+func directionError(direction, suggestion string) error {
+	if suggestion == "" {
+		return fmt.Errorf("unknown language direction: %s", direction)
+	}
+	return fmt.Errorf("unknown language direction: %s, did you mean %s?", direction, suggestion)
+}
+
+// formatDirection returns a direction like "en-ru,de" or "en:zh-Hans" for tags with hyphens,
+// it's empty if some language is unknown.
+func formatDirection(fromLanguage string, targets []string) string {
+	if fromLanguage == "" || slices.Contains(targets, "") {
+		return ""
+	}
+
+	separator := "-"
+	if strings.Contains(fromLanguage, "-") || slices.ContainsFunc(targets, func(target string) bool {
+		return strings.Contains(target, "-")
+	}) {
+		separator = ":"
+	}
+
+	return fromLanguage + separator + strings.Join(targets, ",")
+}
+
+// resolveLanguages replaces language names and close matches by codes of translation languages
+// and checks dictionary directions if dictionary is used.
+// Offline dictionary lookups check only configured pairs, so they don't need network.
+func (y *Handler) resolveLanguages(ctx context.Context, fromLanguage string, targets []string) (string, []string, error) {
+	if y.isDictionary && provider.HasOffline(y.dictionary) {
+		// local dictionaries pairs are set by codes in configuration, translation API is not requested
+		return fromLanguage, targets, y.checkPairs(ctx, fromLanguage, targets)
+	}
+
+	languages, err := y.translationLanguages(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("can not set languages: %w", err)
+	}
+
+	// unknown languages are replaced by suggested ones to build a hint
+	var unknown bool
+	codes := make([]string, 0, len(targets)+1)
+
+	for _, query := range append([]string{fromLanguage}, targets...) {
+		code, ok := resolveLanguage(languages.Languages, query)
+		switch {
+		case !ok:
+			unknown = true
+			code = suggestLanguage(languages.Languages, query)
+		case code != query:
+			y.config.Logger.Printf("language %q is resolved as %q", query, code)
+		}
+		codes = append(codes, code)
+	}
+
+	if unknown {
+		return "", nil, directionError(formatDirection(fromLanguage, targets), formatDirection(codes[0], codes[1:]))
+	}
+
+	fromLanguage, codes = codes[0], codes[1:]
+	if !y.isDictionary {
+		return fromLanguage, codes, nil
+	}
+
+	if err = y.checkPairs(ctx, fromLanguage, codes); err != nil {
+		return "", nil, err
+	}
+
+	return fromLanguage, codes, nil
+}
+
+// checkPairs returns an error if some dictionary direction from the source language to targets is not supported.
+func (y *Handler) checkPairs(ctx context.Context, fromLanguage string, targets []string) error {
+	pairs, err := y.dictionaryLanguages(ctx)
+	if err != nil {
+		return fmt.Errorf("can not set languages: %w", err)
+	}
+
+	for _, target := range targets {
+		if !pairs.Contains(fromLanguage, target) {
+			pair := langtag.Base(fromLanguage) + "-" + langtag.Base(target)
+			return directionError(pair, suggestPair(*pairs, pair))
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,12 @@
 package handle
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/translation"
+)
 
 func TestASCIIDetection(t *testing.T) {
 	cases := []struct {
@@ -39,6 +45,14 @@ func TestSplitDetection(t *testing.T) {
 		{direction: "bad", withError: true},
 		{direction: "too long", withError: true},
 		{direction: "no-ru", fromLanguage: "no", toLanguage: "ru"},
+		{direction: "EN-RU", fromLanguage: En, toLanguage: Ru},
+		{direction: "English-German", fromLanguage: "english", toLanguage: "german"},
+		{direction: "en:zh-hans", fromLanguage: En, toLanguage: "zh-Hans"},
+		{direction: "en->sr-Latn", fromLanguage: En, toLanguage: "sr-Latn"},
+		{direction: "pt_br -> en", fromLanguage: "pt-BR", toLanguage: En},
+		{direction: "en:pt-BR:ru", withError: true},
+		{direction: "en:zh-Hans-Latn", withError: true},
+		{direction: "en:", withError: true},
 	}
 
 	for i, c := range cases {
@@ -78,3 +92,88 @@ func TestKnowLanguages(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitTargets(t *testing.T) {
+	cases := []struct {
+		direction string
+		expected  string
+		targets   []string
+	}{
+		{},
+		{direction: "en-ru", expected: "en-ru"},
+		{direction: "en-ru,de,fr", expected: "en-ru", targets: []string{"de", "fr"}},
+		{direction: "en-ru, de,,", expected: "en-ru", targets: []string{"de"}},
+		{direction: "auto,de", expected: "auto", targets: []string{"de"}},
+		{direction: "en:zh-Hans,sr-Latn", expected: "en:zh-Hans", targets: []string{"sr-Latn"}},
+	}
+
+	for i, c := range cases {
+		direction, targets := splitTargets(c.direction)
+		if direction != c.expected || !slices.Equal(targets, c.targets) {
+			t.Errorf("case %v: expected %q %v, got %q %v", i, c.expected, c.targets, direction, targets)
+		}
+	}
+}
+
+func TestResolveLanguage(t *testing.T) {
+	languages := []translation.Language{
+		{Code: "de", Name: "German"},
+		{Code: "el", Name: "Greek"},
+		{Code: "en", Name: "English"},
+		{Code: "es", Name: "Spanish"},
+		{Code: "sr", Name: "Serbian"},
+		{Code: "sr-Latn", Name: "Serbian (Latin)"},
+		{Code: "sv", Name: "Swedish"},
+	}
+
+	cases := []struct {
+		query      string
+		expected   string
+		suggestion string
+	}{
+		{query: "de", expected: "de", suggestion: "de"},
+		{query: "DE", expected: "de", suggestion: "de"},
+		{query: " german ", expected: "de", suggestion: "de"},
+		{query: "germ", expected: "de", suggestion: "de"},
+		{query: "germen", expected: "de", suggestion: "de"},
+		{query: "Englsh", expected: "en", suggestion: "en"},
+		{query: "gr", suggestion: "el"},
+		{query: "se", suggestion: "sr"},
+		{query: "swe", expected: "sv", suggestion: "sv"},
+		{query: "s", suggestion: "es"},
+		{query: "xyz"},
+		{query: "de-AT", expected: "de", suggestion: "de"},
+		{query: "sr-latn", expected: "sr-Latn", suggestion: "sr-Latn"},
+		{query: "sr-Latn-RS", expected: "sr-Latn", suggestion: "sr-Latn"},
+	}
+
+	for i, c := range cases {
+		code, ok := resolveLanguage(languages, c.query)
+		if ok != (c.expected != "") || (ok && code != c.expected) {
+			t.Errorf("case %v: expected %q, got %q %v", i, c.expected, code, ok)
+		}
+
+		if suggestion := suggestLanguage(languages, c.query); suggestion != c.suggestion {
+			t.Errorf("case %v: expected suggestion %q, got %q", i, c.suggestion, suggestion)
+		}
+	}
+}
+
+func TestSuggestPair(t *testing.T) {
+	languages := dictionary.Languages{"de-en", "de-ru", "en-de", "en-ru"}
+	cases := []struct {
+		pair     string
+		expected string
+	}{
+		{pair: "en-ru", expected: "en-ru"},
+		{pair: "en-gr", expected: "en-de"},
+		{pair: "ru-de", expected: "en-de"},
+		{pair: "fr-it"},
+	}
+
+	for i, c := range cases {
+		if suggestion := suggestPair(languages, c.pair); suggestion != c.expected {
+			t.Errorf("case %v: expected %q, got %q", i, c.expected, suggestion)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package handle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/memory"
+	"github.com/z0rr0/ytapigo/result"
+	"github.com/z0rr0/ytapigo/tmx"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// MemoryCommand is a name of translation memory command.
+const MemoryCommand = "tm"
+
+// translationMemory returns a translation memory, it is nil if the memory is not configured.
+func (y *Handler) translationMemory() (*memory.Memory, error) {
+	if y.config.Memory == "" {
+		return nil, nil
+	}
+
+	y.memoryOnce.Do(func() {
+		y.memory, y.memoryErr = memory.Load(y.config.Memory)
+	})
+
+	return y.memory, y.memoryErr
+}
+
+// saveMemory saves the translation memory if it was loaded.
+func (y *Handler) saveMemory() error {
+	if y.memory == nil {
+		return nil
+	}
+	return y.memory.Save()
+}
+
+// suggestions returns fuzzy matches of the text from translation memory,
+// they are not used for several target languages.
+func (y *Handler) suggestions() (result.Translation, error) {
+	tm, err := y.translationMemory()
+	if err != nil || tm == nil || y.isDictionary || len(y.targets) > 1 {
+		return memory.Suggestions(nil), err
+	}
+
+	threshold := y.config.Threshold
+	if threshold <= 0 {
+		threshold = memory.DefaultThreshold
+	}
+
+	return tm.Fuzzy(y.text, y.fromLanguage, y.toLanguage, threshold), nil
+}
+
+// remember returns a translation function which uses exact matches of translation memory
+// and translates other texts by fn, new translations are added to the memory.
+func (y *Handler) remember(fn translation.BatchFunc) (translation.BatchFunc, error) {
+	tm, err := y.translationMemory()
+	if err != nil || tm == nil {
+		return fn, err
+	}
+
+	return func(ctx context.Context, texts []string) ([]string, error) {
+		var (
+			result  = make([]string, len(texts))
+			pending []int
+			missed  []string
+		)
+
+		for i, text := range texts {
+			if e, ok := tm.Get(text, y.fromLanguage, y.toLanguage); ok {
+				result[i] = e.Target
+				continue
+			}
+
+			pending = append(pending, i)
+			missed = append(missed, text)
+		}
+
+		y.config.Logger.Printf("translation memory exact matches %d of %d", len(texts)-len(missed), len(texts))
+		if len(missed) == 0 {
+			return result, nil
+		}
+
+		translations, err := fn(ctx, missed)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(translations) != len(missed) {
+			return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(missed))
+		}
+
+		for j, i := range pending {
+			result[i] = translations[j]
+			tm.Add(texts[i], translations[j], y.fromLanguage, y.toLanguage)
+		}
+
+		return result, nil
+	}, nil
+}
+
+// RunMemory runs translation memory command, params are a sub-command and its arguments:
+// "list", "search <query>", "edit <id> <translation>", "purge [query]",
+// "import <file.tmx>" or "export [file.tmx]".
+// Entries are filtered by language direction like "en-ru" or "en:pt-BR" if it's not empty.
+func (y *Handler) RunMemory(direction string, params []string) error {
+	tm, err := y.translationMemory()
+	if err != nil {
+		return err
+	}
+
+	if tm == nil {
+		return fmt.Errorf("translation memory is not configured")
+	}
+
+	if len(params) == 0 {
+		return fmt.Errorf("translation memory command is required: list, search, edit, purge, import or export")
+	}
+
+	from, to, ok := splitDirection(direction)
+	if !ok {
+		from, to = direction, "" // only source language or no filter
+	}
+
+	var (
+		filter  = memory.Pair(langtag.Canonical(from), langtag.Canonical(to))
+		command = params[0]
+		query   = strings.Join(params[1:], " ")
+	)
+
+	switch command {
+	case "list":
+		showEntries(tm.List(filter))
+	case "search":
+		if query == "" {
+			return fmt.Errorf("search query is required")
+		}
+		showEntries(tm.Search(query, filter))
+	case "edit":
+		if len(params) < 3 {
+			return fmt.Errorf("entry identifier and translation are required")
+		}
+
+		e, err := tm.Edit(params[1], strings.Join(params[2:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Println(e)
+	case "purge":
+		entries := tm.List(filter)
+		if query != "" {
+			entries = tm.Search(query, filter)
+		}
+		fmt.Printf("purged %d entries\n", tm.Purge(entries))
+	case "import":
+		if query == "" {
+			return fmt.Errorf("TMX file name is required")
+		}
+
+		n, err := importTMX(tm, query)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported %d entries\n", n)
+	case "export":
+		if err = y.exportTMX(tm.List(filter), query); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown translation memory command %q", command)
+	}
+
+	return tm.Save()
+}
+
+// importTMX adds entries of TMX file to the translation memory.
+func importTMX(tm *memory.Memory, fileName string) (int, error) {
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		return 0, fmt.Errorf("read TMX file: %w", err)
+	}
+
+	entries, err := tmx.Read(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	return tm.Import(entries), nil
+}
+
+// exportTMX writes translation memory entries to TMX file or stdout if fileName is empty.
+func (y *Handler) exportTMX(entries []*memory.Entry, fileName string) error {
+	var (
+		b      bytes.Buffer
+		header = tmx.Header{CreationTool: y.options.Name, CreationToolVersion: y.options.Version}
+	)
+
+	if err := tmx.Write(&b, entries, header); err != nil {
+		return err
+	}
+
+	return writeOutput(fileName, b.Bytes())
+}
+
+// showEntries prints translation memory entries.
+func showEntries(entries []*memory.Entry) {
+	for _, e := range entries {
+		fmt.Println(e)
+	}
+}
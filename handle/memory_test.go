@@ -0,0 +1,191 @@
+package handle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/memory"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+func TestHandler_remember(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Logger:      logger,
+		Memory:      filepath.Join(t.TempDir(), "memory.json"),
+		URL: map[string]string{
+			translation.URL: s.URL + "/translate/v2/translate",
+			spelling.URL:    s.URL + "/services/spellservice.json/checkText",
+		},
+	}
+
+	h, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Run(context.Background(), "en-ru", []string{"time", "to", "start"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tm, err := memory.Load(cfg.Memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e, ok := tm.Get("time to start", "en", "ru"); !ok || e.Target != "пора начинать" {
+		t.Fatalf("unexpected memory entry %v", e)
+	}
+
+	var requested []string
+	fn, err := h.remember(func(_ context.Context, texts []string) ([]string, error) {
+		requested = append(requested, texts...)
+		return texts, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := fn(context.Background(), []string{"Time  to start", "new text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "пора начинать|new text"; strings.Join(result, "|") != expected {
+		t.Errorf("expected %q, got %q", expected, strings.Join(result, "|"))
+	}
+
+	if expected := "new text"; strings.Join(requested, "|") != expected {
+		t.Errorf("expected requested %q, got %q", expected, strings.Join(requested, "|"))
+	}
+
+	var (
+		tmxFile  = filepath.Join(t.TempDir(), "input.tmx")
+		exported = filepath.Join(t.TempDir(), "output.tmx")
+		tmxData  = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4"><header srclang="en"/><body>
+  <tu><tuv xml:lang="en"><seg>Good morning</seg></tuv><tuv xml:lang="RU"><seg>Доброе утро</seg></tuv></tu>
+  <tu><tuv xml:lang="en"><seg>Thank you</seg></tuv><tuv xml:lang="pt_br"><seg>Obrigado</seg></tuv></tu>
+</body></tmx>`
+	)
+
+	if err = os.WriteFile(tmxFile, []byte(tmxData), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := []struct {
+		params []string
+		err    string
+	}{
+		{params: []string{"list"}},
+		{params: []string{"search", "start"}},
+		{params: []string{"search"}, err: "search query is required"},
+		{params: []string{"edit", tm.List(memory.Pair("", ""))[0].ID(), "самое", "время"}},
+		{params: []string{"edit", "unknown"}, err: "entry identifier and translation are required"},
+		{params: []string{"purge", "new"}},
+		{params: []string{"unknown"}, err: `unknown translation memory command "unknown"`},
+		{err: "translation memory command is required: list, search, edit, purge, import or export"},
+		{params: []string{"import", tmxFile}},
+		{params: []string{"import"}, err: "TMX file name is required"},
+		{params: []string{"export", exported}},
+	}
+
+	for _, c := range commands {
+		err = h.RunMemory("en-ru", c.params)
+		if c.err == "" && err != nil {
+			t.Errorf("unexpected error for %v: %v", c.params, err)
+		}
+
+		if c.err != "" && (err == nil || err.Error() != c.err) {
+			t.Errorf("expected error %q for %v, got %v", c.err, c.params, err)
+		}
+	}
+
+	if tm, err = memory.Load(cfg.Memory); err != nil {
+		t.Fatal(err)
+	}
+
+	if e, ok := tm.Get("time to start", "en", "ru"); !ok || e.Target != "самое время" {
+		t.Errorf("unexpected edited entry %v", e)
+	}
+
+	if e, ok := tm.Get("good morning", "en", "ru"); !ok || e.Target != "Доброе утро" {
+		t.Errorf("unexpected imported entry %v", e)
+	}
+
+	// languages are normalized like translation directions "en:pt-BR"
+	if e, ok := tm.Get("thank you", "en", "pt-BR"); !ok || e.Target != "Obrigado" {
+		t.Errorf("unexpected imported entry %v", e)
+	}
+
+	if n := len(tm.List(memory.Pair("", ""))); n != 3 {
+		t.Errorf("unexpected entries count %d", n)
+	}
+
+	data, err := os.ReadFile(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), "<seg>Доброе утро</seg>") {
+		t.Errorf("unexpected exported TMX %s", data)
+	}
+}
+
+func TestHandler_rememberRegional(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	translator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected translation request %s", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer translator.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Logger:      logger,
+		Memory:      filepath.Join(t.TempDir(), "memory.json"),
+		URL: map[string]string{
+			translation.URL: translator.URL + "/translate/v2/translate",
+			spelling.URL:    s.URL + "/services/spellservice.json/checkText",
+		},
+	}
+
+	var (
+		tmxFile = filepath.Join(t.TempDir(), "input.tmx")
+		tmxData = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4"><header srclang="en-US"/><body>
+  <tu><tuv xml:lang="en-US"><seg>Good morning</seg></tuv><tuv xml:lang="ru-RU"><seg>Доброе утро</seg></tuv></tu>
+</body></tmx>`
+	)
+
+	if err := os.WriteFile(tmxFile, []byte(tmxData), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = h.RunMemory("", []string{"import", tmxFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	// regional tags of TMX entries match the primary languages of the direction
+	if err = h.Run(context.Background(), "en-ru", []string{"good", "morning"}); err != nil {
+		t.Fatal(err)
+	}
+}
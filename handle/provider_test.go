@@ -0,0 +1,444 @@
+package handle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/libre"
+	"github.com/z0rr0/ytapigo/provider"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// fakeProvider implements translation, dictionary and spelling check providers without HTTP requests,
+// it saves requested languages.
+type fakeProvider struct {
+	sync.Mutex
+	requests []string
+}
+
+func (f *fakeProvider) save(kind, from, to, text string) {
+	f.Lock()
+	defer f.Unlock()
+	f.requests = append(f.requests, kind+" "+from+"-"+to+" "+text)
+}
+
+func (f *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *fakeProvider) Translate(_ context.Context, r *translation.Request) (*translation.Response, error) {
+	response := &translation.Response{}
+	for _, text := range r.Texts {
+		f.save("translate", r.SourceLanguageCode, r.TargetLanguageCode, text)
+		response.Translations = append(response.Translations, translation.ResponseItem{Text: strings.ToUpper(text)})
+	}
+	return response, nil
+}
+
+func (f *fakeProvider) Detect(_ context.Context, text string) (string, error) {
+	f.save("detect", "", "", text)
+	return "de", nil
+}
+
+func (f *fakeProvider) Languages(context.Context) (*translation.Languages, error) {
+	return translation.NewLanguages([]translation.Language{{Code: "de", Name: "German"}, {Code: "ru", Name: "Russian"}}), nil
+}
+
+// fakeDictionary is a dictionary provider based on fakeProvider.
+type fakeDictionary struct {
+	*fakeProvider
+}
+
+func (f fakeDictionary) Lookup(_ context.Context, r *dictionary.Request) (*dictionary.Response, error) {
+	f.save("lookup", r.SourceLanguageCode, r.TargetLanguageCode, r.Text)
+	return &dictionary.Response{}, nil
+}
+
+func (f fakeDictionary) Languages(context.Context) (*dictionary.Languages, error) {
+	return &dictionary.Languages{"de-ru"}, nil
+}
+
+// fakeSpeller is a spelling check provider based on fakeProvider.
+type fakeSpeller struct {
+	*fakeProvider
+}
+
+func (f fakeSpeller) Check(_ context.Context, lang, text string) (*spelling.Response, error) {
+	f.save("spelling", lang, "", text)
+	return &spelling.Response{}, nil
+}
+
+func (f fakeSpeller) Languages() []string {
+	return []string{"de"}
+}
+
+func TestHandler_providers(t *testing.T) {
+	f := &fakeProvider{}
+	h, err := New(&config.Config{Logger: logger}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.translator, h.dictionary, h.speller = f, fakeDictionary{f}, fakeSpeller{f}
+
+	if err = h.Run(context.Background(), AutoLanguageDetect, []string{"guten", "tag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = h.Run(context.Background(), "German-russian", []string{"tag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"detect - guten tag", "spelling de- guten tag", "translate de-ru guten tag",
+		"spelling de- tag", "lookup de-ru tag", "translate de-ru tag", // no dictionary articles
+	}
+	if len(f.requests) != len(expected) {
+		t.Fatalf("expected requests %q, got %q", expected, f.requests)
+	}
+
+	// spelling check and translation are concurrent
+	for _, r := range expected {
+		if !strings.Contains(strings.Join(f.requests, "\n"), r) {
+			t.Errorf("request %q not found in %q", r, f.requests)
+		}
+	}
+}
+
+func TestHandler_libreTranslate(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case libre.LanguagesPath:
+			response = `[{"code":"en","name":"English","targets":["ru"]},{"code":"ru","name":"Russian","targets":["en"]}]`
+		case libre.DetectPath:
+			response = `[{"confidence":90,"language":"en"}]`
+		case libre.TranslatePath:
+			response = `{"translatedText":["пора начинать"]}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := fmt.Fprint(w, response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer s.Close()
+
+	cfg := &config.Config{
+		Providers:      config.Providers{Translation: config.Names{provider.LibreTranslate}},
+		LibreTranslate: config.LibreTranslate{URL: s.URL},
+		Logger:         logger,
+	}
+
+	testCases := []struct {
+		name      string
+		direction string
+		err       string
+	}{
+		{name: "auto", direction: AutoLanguageDetect},
+		{name: "direction", direction: "english-russian"},
+		{name: "unknown", direction: "en-de", err: "unknown language direction: en-de"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(cfg, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name := h.translator.Name(); name != provider.LibreTranslate {
+				t.Fatalf("unexpected translator %q", name)
+			}
+			h.speller = fakeSpeller{&fakeProvider{}}
+
+			err = h.Run(context.Background(), tc.direction, []string{"time", "to", "start"})
+			if tc.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestHandler_libreTranslateOnly(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response string
+		switch r.URL.Path {
+		case libre.LanguagesPath:
+			response = `[{"code":"en","name":"English","targets":["ru"]},{"code":"ru","name":"Russian","targets":["en"]}]`
+		case libre.DetectPath:
+			response = `[{"confidence":90,"language":"en"}]`
+		case libre.TranslatePath:
+			response = `{"translatedText":["время"]}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := fmt.Fprint(w, response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer s.Close()
+
+	yandex := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected Yandex request %s", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer yandex.Close()
+
+	cfg := &config.Config{
+		Providers: config.Providers{
+			Translation: config.Names{provider.LibreTranslate},
+			Dictionary:  config.Names{provider.None},
+			Spelling:    config.Names{provider.None},
+		},
+		LibreTranslate: config.LibreTranslate{URL: s.URL},
+		Logger:         logger,
+		URL: map[string]string{
+			dictionary.LanguagesURL:       yandex.URL,
+			dictionary.TranslationURL:     yandex.URL,
+			translation.LanguagesURL:      yandex.URL,
+			translation.URL:               yandex.URL,
+			translation.DetectLanguageURL: yandex.URL,
+			spelling.URL:                  yandex.URL,
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		direction string
+		options   Options
+		params    []string
+	}{
+		{name: "word", direction: "en-ru", params: []string{"time"}},
+		{name: "text", direction: AutoLanguageDetect, params: []string{"time", "to", "start"}},
+		{name: "explain", direction: "english-russian", options: Options{Explain: true}, params: []string{"time", "to", "start"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(cfg, tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = h.Run(context.Background(), tc.direction, tc.params); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestHandler_offlineDictionary(t *testing.T) {
+	dir := t.TempDir()
+
+	// dictd database with one 22 bytes article, "W" is base64 encoded size
+	if err := os.WriteFile(filepath.Join(dir, "en-ru.index"), []byte("apple\tA\tW\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "en-ru.dict"), []byte("apple\n   яблоко\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// dictd database with one 15 bytes article, "P" is base64 encoded size
+	if err := os.WriteFile(filepath.Join(dir, "en-de.index"), []byte("apple\tA\tP\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "en-de.dict"), []byte("apple\n   Apfel\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Providers: config.Providers{Dictionary: config.Names{provider.Offline}},
+		Offline: map[string]string{
+			"en-ru": filepath.Join(dir, "en-ru.index"),
+			"en-de": filepath.Join(dir, "en-de.index"),
+		},
+		Logger: logger,
+		// spelling check and translation languages must not be requested
+		URL: map[string]string{spelling.URL: "http://localhost:1", translation.LanguagesURL: "http://localhost:1"},
+	}
+
+	h, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = h.Run(context.Background(), "en-ru", []string{"apple"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = h.Run(context.Background(), "en-de", []string{"apple"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = h.Run(context.Background(), "en-fr", []string{"apple"})
+	if expected := "unknown language direction: en-fr, did you mean en-de?"; err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	// fallback chain with local dictionaries doesn't check spelling and translation languages too
+	s := testServer(t)
+	defer s.Close()
+
+	cfg.Providers.Dictionary = config.Names{provider.Offline, provider.Yandex}
+	cfg.LanguagesCache = filepath.Join(dir, "languages.json")
+	cfg.URL[dictionary.LanguagesURL] = s.URL + "/api/v1/dicservice.json/getLangs"
+
+	if h, err = New(cfg, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = h.Run(context.Background(), "en-de", []string{"apple"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// pairs of configured local dictionaries are not cached
+	if _, err = os.Stat(cfg.LanguagesCache); !os.IsNotExist(err) {
+		t.Errorf("unexpected languages cache: %v", err)
+	}
+}
+
+func TestHandler_fallback(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	cfg := &config.Config{
+		Translation:    cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Providers:      config.Providers{Translation: config.Names{provider.LibreTranslate, provider.Yandex}},
+		LibreTranslate: config.LibreTranslate{URL: unavailable.URL},
+		HealthCache:    filepath.Join(t.TempDir(), "health.json"),
+		Logger:         logger,
+		URL:            map[string]string{translation.URL: s.URL + "/translate/v2/translate"},
+	}
+
+	testCases := []struct {
+		name     string
+		targets  []string
+		expected []string
+	}{
+		{name: "single", targets: []string{"ru"}, expected: []string{"[yandex]\nпора начинать"}},
+		{name: "cooldown", targets: []string{"ru", "de"}, expected: []string{"[ru, yandex]\nпора начинать", "[de, yandex]\nпора начинать"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(cfg, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.speller = fakeSpeller{&fakeProvider{}}
+			h.text, h.fromLanguage, h.toLanguage, h.targets = "time to start", "en", tc.targets[0], tc.targets
+
+			results, err := h.translationAndSpelling(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the first result is spelling check
+			if len(results) != len(tc.expected)+1 {
+				t.Fatalf("unexpected results count %d", len(results))
+			}
+
+			for i, expected := range tc.expected {
+				if s := results[i+1].String(); s != expected {
+					t.Errorf("expected %q, got %q", expected, s)
+				}
+			}
+		})
+	}
+
+	data, err := os.ReadFile(cfg.HealthCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"translation:libretranslate"`) {
+		t.Errorf("failure is not saved: %s", data)
+	}
+}
+
+func TestHandler_modes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mode     string
+		params   []string
+		expected []string
+	}{
+		{
+			name:     "auto_fallback",
+			params:   []string{"tag"},
+			expected: []string{"spelling de- tag", "lookup de-ru tag", "translate de-ru tag"},
+		},
+		{
+			name:     "auto_text",
+			params:   []string{"guten", "tag"},
+			expected: []string{"spelling de- guten tag", "translate de-ru guten tag"},
+		},
+		{
+			name:     "dictionary",
+			mode:     ModeDictionary,
+			params:   []string{"guten", "tag"},
+			expected: []string{"spelling de- guten tag", "lookup de-ru guten tag"},
+		},
+		{
+			name:     "translate",
+			mode:     ModeTranslate,
+			params:   []string{"tag"},
+			expected: []string{"spelling de- tag", "translate de-ru tag"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &fakeProvider{}
+			h, err := New(&config.Config{Logger: logger}, Options{Mode: tc.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.translator, h.dictionary, h.speller = f, fakeDictionary{f}, fakeSpeller{f}
+
+			if err = h.Run(context.Background(), "de-ru", tc.params); err != nil {
+				t.Fatal(err)
+			}
+
+			slices.Sort(f.requests) // spelling check is concurrent
+			slices.Sort(tc.expected)
+
+			if !slices.Equal(f.requests, tc.expected) {
+				t.Errorf("expected requests %q, got %q", tc.expected, f.requests)
+			}
+		})
+	}
+}
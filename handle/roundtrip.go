@@ -0,0 +1,24 @@
+package handle
+
+import (
+	"context"
+
+	"github.com/z0rr0/ytapigo/result"
+	"github.com/z0rr0/ytapigo/roundtrip"
+)
+
+// roundTrip translates the text to the target language and immediately back to the source one.
+// Back-translation doesn't use translation memory, so it checks the translation itself.
+func (y *Handler) roundTrip(ctx context.Context, toLanguage string) (result.Translation, error) {
+	forward, err := y.translation(ctx, toLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	back, err := y.translateText(ctx, forward.String(), toLanguage, y.fromLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roundtrip.Result{Original: y.text, Translation: forward.String(), Back: back.String()}, nil
+}
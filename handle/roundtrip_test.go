@@ -0,0 +1,55 @@
+package handle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/roundtrip"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+func TestHandler_roundTrip(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Logger:      logger,
+		URL: map[string]string{
+			translation.URL: s.URL + "/translate/v2/translate",
+			spelling.URL:    s.URL + "/services/spellservice.json/checkText",
+		},
+	}
+
+	h, err := New(cfg, Options{Back: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one word is translated by translation API, not a dictionary
+	if err := h.Run(context.Background(), "en-ru,uk", []string{"time"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if h.isDictionary {
+		t.Error("dictionary mode is not expected")
+	}
+
+	r, err := h.roundTrip(context.Background(), Ru)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, ok := r.(*roundtrip.Result)
+	if !ok {
+		t.Fatalf("unexpected result type %T", r)
+	}
+
+	expected := roundtrip.Result{Original: "time", Translation: "пора начинать", Back: "пора начинать"}
+	if *item != expected {
+		t.Errorf("expected %v, got %v", expected, *item)
+	}
+}
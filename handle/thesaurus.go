@@ -0,0 +1,106 @@
+package handle
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/arguments"
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/thesaurus"
+)
+
+// ThesaurusCommand is a name of same-language dictionary lookup command.
+const ThesaurusCommand = "thesaurus"
+
+// RunThesaurus prints synonyms of the text grouped by parts of speech and meanings.
+// Direction is one language like "en" or "english", a pair like "en-en" uses its source language.
+// Synonyms of every found synonym are looked up too if reverse option is set.
+func (y *Handler) RunThesaurus(ctx context.Context, direction string, params []string) error {
+	r, err := y.thesaurus(ctx, direction, params)
+	if err != nil {
+		return err
+	}
+
+	if !r.Exists() {
+		return fmt.Errorf("no synonyms found for %q", y.text)
+	}
+
+	fmt.Println(r)
+	return nil
+}
+
+// thesaurus does a same-language dictionary lookup of the text and reverse lookups of its synonyms.
+func (y *Handler) thesaurus(ctx context.Context, direction string, params []string) (*thesaurus.Result, error) {
+	if y.text, _ = arguments.Text(params); y.text == "" {
+		return nil, fmt.Errorf("thesaurus text is required")
+	}
+
+	if err := y.thesaurusLanguage(ctx, direction); err != nil {
+		return nil, err
+	}
+
+	request, err := y.dictionaryRequest(y.text, y.fromLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := y.dictionary.Lookup(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	r := thesaurus.New(y.text, response.Apply(&y.options.View))
+	if !y.options.Reverse {
+		return r, nil
+	}
+
+	synonyms := r.Synonyms()
+	responses, err := y.lookups(ctx, synonyms, y.fromLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, response := range responses {
+		if response == nil {
+			continue // failed lookup
+		}
+
+		reverse := slices.DeleteFunc(thesaurus.New(synonyms[i], response).Synonyms(), func(s string) bool {
+			return strings.EqualFold(s, synonyms[i]) // an article text can be a base form of the synonym
+		})
+
+		if len(reverse) > 0 {
+			r.Reverse = append(r.Reverse, thesaurus.Reverse{Word: synonyms[i], Synonyms: reverse})
+		}
+	}
+
+	return r, nil
+}
+
+// thesaurusLanguage sets the same source and target language of the dictionary lookup.
+// It is detected like a source language of translation if the direction is empty or auto.
+func (y *Handler) thesaurusLanguage(ctx context.Context, direction string) error {
+	if _, _, ok := splitDirection(direction); !ok && direction != "" && direction != AutoLanguageDetect {
+		direction += "-" + direction // one language
+	}
+
+	if err := y.setLanguages(ctx, direction); err != nil {
+		return err
+	}
+
+	y.toLanguage, y.targets = y.fromLanguage, []string{y.fromLanguage}
+
+	pairs, err := y.dictionaryLanguages(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !pairs.Contains(y.fromLanguage, y.fromLanguage) {
+		pair := langtag.Base(y.fromLanguage) + "-" + langtag.Base(y.fromLanguage)
+		return directionError(pair, suggestPair(*pairs, pair))
+	}
+
+	return nil
+}
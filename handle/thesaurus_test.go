@@ -0,0 +1,90 @@
+package handle
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/thesaurus"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+func TestHandler_thesaurus(t *testing.T) {
+	s := testServer(t)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Logger:      logger,
+		URL: map[string]string{
+			dictionary.LanguagesURL:   s.URL + "/api/v1/dicservice.json/getLangs",
+			dictionary.TranslationURL: s.URL + "/api/v1/dicservice.json/lookup",
+			translation.LanguagesURL:  s.URL + "/translate/v2/languages",
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		direction string
+		params    []string
+		reverse   bool
+		err       bool
+	}{
+		{name: "code", direction: "en", params: []string{"time"}},
+		{name: "pair", direction: "en-en", params: []string{"time"}},
+		{name: "name", direction: "English", params: []string{"time"}},
+		{name: "detection", params: []string{"time"}},
+		{name: "reverse", direction: "en", params: []string{"time"}, reverse: true},
+		{name: "no_text", direction: "en", params: []string{" "}, err: true},
+		{name: "no_pair", direction: "ru", params: []string{"время"}, err: true},
+	}
+
+	groups := []thesaurus.Group{
+		{Pos: "noun", Synonyms: []string{"время", "раз", "момент"}, Meanings: []string{"timing", "fold", "half"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(cfg, Options{Reverse: tc.reverse})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := h.thesaurus(context.Background(), tc.direction, tc.params)
+			if tc.err {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if h.fromLanguage != En || h.toLanguage != En {
+				t.Errorf("unexpected direction %s-%s", h.fromLanguage, h.toLanguage)
+			}
+
+			if r.Text != "time" || r.Ts != "taɪm" || !slices.EqualFunc(r.Groups, groups, equalGroups) {
+				t.Errorf("unexpected result %+v", r)
+			}
+
+			// test server returns the same article for every synonym
+			if n := len(r.Reverse); (n == 3) != tc.reverse {
+				t.Errorf("unexpected reverse lookups %v", r.Reverse)
+			}
+
+			if tc.reverse && !slices.Equal(r.Reverse[0].Synonyms, []string{"раз", "момент"}) {
+				t.Errorf("unexpected reverse synonyms %v", r.Reverse[0])
+			}
+		})
+	}
+}
+
+func equalGroups(a, b thesaurus.Group) bool {
+	return a.Pos == b.Pos && slices.Equal(a.Synonyms, b.Synonyms) && slices.Equal(a.Meanings, b.Meanings)
+}
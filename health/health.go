@@ -0,0 +1,101 @@
+// Package health remembers failures of providers, so unavailable ones are not used during a cool-down period.
+// Failures are kept in a local file between runs if its name is set.
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Tracker keeps last failure times of providers by their keys like "dictionary:yandex".
+type Tracker struct {
+	sync.Mutex
+	fileName string
+	cooldown time.Duration
+	failures map[string]time.Time
+	now      func() time.Time
+}
+
+// New returns a tracker with failures from the file,
+// previous failures are ignored if reset is true.
+func New(fileName string, cooldown time.Duration, reset bool) (*Tracker, error) {
+	t := &Tracker{fileName: fileName, cooldown: cooldown, failures: make(map[string]time.Time), now: time.Now}
+	if fileName == "" || reset {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return t, nil
+		}
+		return nil, fmt.Errorf("read health cache: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &t.failures); err != nil {
+		return nil, fmt.Errorf("parse health cache %q: %w", fileName, err)
+	}
+
+	return t, nil
+}
+
+// Available returns true if the provider has no failures during cool-down period.
+func (t *Tracker) Available(key string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	failed, ok := t.failures[key]
+	return !ok || t.now().Sub(failed) >= t.cooldown
+}
+
+// Fail remembers the provider failure.
+func (t *Tracker) Fail(key string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	t.failures[key] = t.now().UTC()
+	return t.write()
+}
+
+// Success forgets the provider failure.
+func (t *Tracker) Success(key string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, ok := t.failures[key]; !ok {
+		return nil
+	}
+
+	delete(t.failures, key)
+	return t.write()
+}
+
+// write saves not expired failures to the file if its name is set.
+func (t *Tracker) write() error {
+	if t.fileName == "" {
+		return nil
+	}
+
+	now := t.now()
+	maps.DeleteFunc(t.failures, func(_ string, failed time.Time) bool {
+		return now.Sub(failed) >= t.cooldown
+	})
+
+	data, err := json.MarshalIndent(t.failures, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode health cache: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Clean(t.fileName), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write health cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	const key = "dictionary:yandex"
+	fileName := filepath.Join(t.TempDir(), "health.json")
+
+	tracker, err := New(fileName, time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tracker.Available(key) {
+		t.Error("provider without failures is not available")
+	}
+
+	if err = tracker.Fail(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// failures are read from the file by a new tracker
+	tracker, err = New(fileName, time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tracker.Available(key) {
+		t.Error("failed provider is available")
+	}
+
+	if !tracker.Available("translation:yandex") {
+		t.Error("other provider is not available")
+	}
+
+	tracker.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if !tracker.Available(key) {
+		t.Error("provider is not available after cool-down")
+	}
+
+	if err = tracker.Fail(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tracker.Success(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if !tracker.Available(key) {
+		t.Error("provider is not available after success")
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := strings.TrimSpace(string(data)); s != "{}" {
+		t.Errorf("unexpected health cache %q", s)
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "broken.json")
+
+	if err := os.WriteFile(fileName, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(fileName, time.Minute, false); err == nil || !strings.HasPrefix(err.Error(), "parse health cache") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tracker, err := New(fileName, time.Minute, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// broken file is overwritten
+	if err = tracker.Fail("spelling:yandex"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = New(fileName, time.Minute, false); err != nil {
+		t.Error(err)
+	}
+
+	tracker, err = New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tracker.Fail("spelling:yandex"); err != nil {
+		t.Fatal(err)
+	}
+
+	if tracker.Available("spelling:yandex") {
+		t.Error("failed provider is available without cache file")
+	}
+}
@@ -0,0 +1,43 @@
+// Package testfn contains fake translation functions for tests of files translation.
+package testfn
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+var tagRegexp = regexp.MustCompile(`<[^<>]*>`)
+
+// Upper is a fake translation function, it converts texts to upper case.
+func Upper(_ context.Context, texts []string) ([]string, error) {
+	result := make([]string, len(texts))
+	for i, text := range texts {
+		result[i] = strings.ToUpper(text)
+	}
+	return result, nil
+}
+
+// UpperText is a fake HTML translation function, it converts texts outside tags to upper case,
+// so protected placeholders and markup are kept as is.
+func UpperText(_ context.Context, texts []string) ([]string, error) {
+	result := make([]string, len(texts))
+
+	for i, text := range texts {
+		var (
+			b     strings.Builder
+			start int
+		)
+
+		for _, loc := range tagRegexp.FindAllStringIndex(text, -1) {
+			b.WriteString(strings.ToUpper(text[start:loc[0]]))
+			b.WriteString(text[loc[0]:loc[1]])
+			start = loc[1]
+		}
+
+		b.WriteString(strings.ToUpper(text[start:]))
+		result[i] = b.String()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,160 @@
+// Package langcache keeps supported translation and dictionary languages lists of providers in a local file,
+// so language directions are validated without API requests while the cached lists are fresh.
+package langcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// List is a cached languages list with its update time.
+type List[T any] struct {
+	Updated time.Time `json:"updated"`
+	Items   T         `json:"items"`
+}
+
+// Cache is cached languages lists of one provider.
+type Cache struct {
+	Translation *List[[]translation.Language] `json:"translation,omitempty"`
+	Dictionary  *List[dictionary.Languages]   `json:"dictionary,omitempty"`
+}
+
+// mu guards read-update-write operations with the cache file.
+var mu sync.Mutex
+
+// Translation returns translation languages of the provider from the cache
+// or loads them by fetch function and updates the cache.
+func Translation(cfg *config.Config, provider string, fetch func() (*translation.Languages, error)) (*translation.Languages, error) {
+	section := func(c *Cache) **List[[]translation.Language] { return &c.Translation }
+
+	items, err := load(cfg, provider, section, func() ([]translation.Language, error) {
+		languages, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		return languages.Languages, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return translation.NewLanguages(items), nil
+}
+
+// Dictionary returns dictionary languages of the provider from the cache
+// or loads them by fetch function and updates the cache.
+func Dictionary(cfg *config.Config, provider string, fetch func() (*dictionary.Languages, error)) (*dictionary.Languages, error) {
+	section := func(c *Cache) **List[dictionary.Languages] { return &c.Dictionary }
+
+	items, err := load(cfg, provider, section, func() (dictionary.Languages, error) {
+		languages, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		return *languages, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	items.Sort()
+	return &items, nil
+}
+
+// load returns a fresh cached list from the provider section or fetches a new one and saves it.
+// An expired list is used only if fetching failed and refresh is not required.
+// Failed saving of the cache file is logged, it doesn't break fetched lists.
+func load[T any](cfg *config.Config, provider string, section func(*Cache) **List[T], fetch func() (T, error)) (T, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	caches, err := read(cfg.LanguagesCache)
+	if err != nil {
+		if !cfg.Refresh {
+			var empty T
+			return empty, err
+		}
+		caches = make(map[string]*Cache) // broken cache will be overwritten
+	}
+
+	cache := caches[provider]
+	if cache == nil {
+		cache = &Cache{}
+		caches[provider] = cache
+	}
+
+	cached := *section(cache)
+	if cached != nil && !cfg.Refresh && time.Since(cached.Updated) < cfg.LanguagesLifetime() {
+		cfg.Logger.Printf("languages are read from cache updated at %s", cached.Updated.Format(time.DateTime))
+		return cached.Items, nil
+	}
+
+	items, err := fetch()
+	if err != nil {
+		if cached != nil && !cfg.Refresh {
+			cfg.Logger.Printf("expired languages cache is used: %v", err)
+			return cached.Items, nil
+		}
+		return items, err
+	}
+
+	*section(cache) = &List[T]{Updated: time.Now().UTC(), Items: items}
+	if err = write(cfg.LanguagesCache, caches); err != nil {
+		cfg.Logger.Printf("languages cache is not saved: %v", err) // loaded lists are still valid
+	}
+
+	return items, nil
+}
+
+// read reads the cache file with lists of providers by their names,
+// it returns an empty cache if the file is not set or does not exist.
+func read(fileName string) (map[string]*Cache, error) {
+	caches := make(map[string]*Cache)
+	if fileName == "" {
+		return caches, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return caches, nil
+		}
+		return nil, fmt.Errorf("read languages cache: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &caches); err != nil {
+		return nil, fmt.Errorf("parse languages cache %q: %w", fileName, err)
+	}
+
+	return caches, nil
+}
+
+// write saves the cache file if its name is set.
+func write(fileName string, caches map[string]*Cache) error {
+	if fileName == "" {
+		return nil // no file name, no cache
+	}
+
+	data, err := json.MarshalIndent(caches, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode languages cache: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Clean(fileName), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write languages cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package langcache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+var logger = log.New(os.Stdout, "TEST ", log.Lmicroseconds|log.Lshortfile)
+
+// testServer returns languages lists and counts requests, it fails if failed is set.
+func testServer(t *testing.T, requests *atomic.Int32, failed *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failed.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		body := `["en-ru", "en-en"]`
+		if r.URL.Path == "/translation" {
+			body = `{"languages":[{"code":"en","name":"English"},{"code":"ru","name":"Russian"}]}`
+		}
+
+		if _, e := fmt.Fprint(w, body); e != nil {
+			t.Error(e)
+		}
+	}))
+}
+
+func TestLoad(t *testing.T) {
+	var (
+		requests atomic.Int32
+		failed   atomic.Bool
+		ctx      = context.Background()
+		s        = testServer(t, &requests, &failed)
+		fileName = filepath.Join(t.TempDir(), "languages.json")
+	)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation:    cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		Dictionary:     "test",
+		LanguagesCache: fileName,
+		Logger:         logger,
+		URL: map[string]string{
+			translation.LanguagesURL: s.URL + "/translation",
+			dictionary.LanguagesURL:  s.URL + "/dictionary",
+		},
+	}
+
+	check := func(name string, expectedRequests int32) {
+		t.Helper()
+
+		tl, err := Translation(cfg, "test", func() (*translation.Languages, error) {
+			return translation.LoadLanguages(ctx, s.Client(), cfg)
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		dl, err := Dictionary(cfg, "test", func() (*dictionary.Languages, error) {
+			return dictionary.LoadLanguages(ctx, s.Client(), cfg)
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !tl.Contains("EN", "ru") || !dl.Contains("en", "ru") || dl.Contains("ru", "en") {
+			t.Errorf("%s: unexpected languages: %v, %v", name, tl, dl)
+		}
+
+		if n := requests.Load(); n != expectedRequests {
+			t.Errorf("%s: expected %d requests, got %d", name, expectedRequests, n)
+		}
+	}
+
+	check("empty cache", 2)
+	check("fresh cache", 2)
+
+	cfg.Refresh = true
+	check("refresh", 4)
+
+	cfg.Refresh = false
+	if err := os.WriteFile(fileName, []byte(`{"test":{"translation":{"updated":"2020-01-02T03:04:05Z",`+
+		`"items":[{"code":"en","name":"English"},{"code":"ru","name":"Russian"}]},`+
+		`"dictionary":{"updated":"2020-01-02T03:04:05Z","items":["en-ru"]}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	failed.Store(true)
+	check("expired cache with failed requests", 6)
+
+	cfg.Refresh = true
+	if _, err := Translation(cfg, "test", func() (*translation.Languages, error) {
+		return translation.LoadLanguages(ctx, s.Client(), cfg)
+	}); err == nil {
+		t.Error("expected error for failed refresh")
+	}
+
+	failed.Store(false)
+	check("expired cache", 9)
+
+	cache, err := read(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := time.Since(cache["test"].Dictionary.Updated); d < 0 || d > time.Minute {
+		t.Errorf("unexpected dictionary update time: %v", cache["test"].Dictionary.Updated)
+	}
+}
+
+func TestLoad_NoCache(t *testing.T) {
+	var (
+		requests atomic.Int32
+		failed   atomic.Bool
+		s        = testServer(t, &requests, &failed)
+	)
+	defer s.Close()
+
+	cfg := &config.Config{Dictionary: "test", Logger: logger, URL: map[string]string{dictionary.LanguagesURL: s.URL}}
+	for range 2 {
+		if _, err := Dictionary(cfg, "test", func() (*dictionary.Languages, error) {
+			return dictionary.LoadLanguages(context.Background(), s.Client(), cfg)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestLoad_Broken(t *testing.T) {
+	var (
+		requests atomic.Int32
+		failed   atomic.Bool
+		s        = testServer(t, &requests, &failed)
+		fileName = filepath.Join(t.TempDir(), "languages.json")
+	)
+	defer s.Close()
+
+	if err := os.WriteFile(fileName, []byte(`{"dictionary":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Dictionary:     "test",
+		LanguagesCache: fileName,
+		Logger:         logger,
+		URL:            map[string]string{dictionary.LanguagesURL: s.URL},
+	}
+
+	if _, err := Dictionary(cfg, "test", func() (*dictionary.Languages, error) {
+		return dictionary.LoadLanguages(context.Background(), s.Client(), cfg)
+	}); err == nil {
+		t.Error("expected error for broken cache")
+	}
+
+	cfg.Refresh = true
+	languages, err := Dictionary(cfg, "test", func() (*dictionary.Languages, error) {
+		return dictionary.LoadLanguages(context.Background(), s.Client(), cfg)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if languages.Len() != 2 || requests.Load() != 1 {
+		t.Errorf("unexpected languages %v after %d requests", languages, requests.Load())
+	}
+}
+
+func TestLoad_ReadOnly(t *testing.T) {
+	var (
+		requests atomic.Int32
+		failed   atomic.Bool
+		s        = testServer(t, &requests, &failed)
+		fileName = filepath.Join(t.TempDir(), "missing", "languages.json")
+	)
+	defer s.Close()
+
+	cfg := &config.Config{
+		Dictionary:     "test",
+		LanguagesCache: fileName,
+		Logger:         logger,
+		URL:            map[string]string{dictionary.LanguagesURL: s.URL},
+	}
+
+	// cache directory doesn't exist, so the file is not written
+	languages, err := Dictionary(cfg, "test", func() (*dictionary.Languages, error) {
+		return dictionary.LoadLanguages(context.Background(), s.Client(), cfg)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if languages.Len() != 2 {
+		t.Errorf("unexpected languages %v", languages)
+	}
+
+	if _, err = os.Stat(fileName); !os.IsNotExist(err) {
+		t.Errorf("unexpected cache file: %v", err)
+	}
+}
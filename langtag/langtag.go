@@ -0,0 +1,173 @@
+// Package langtag parses and validates BCP-47 language tags like "en", "pt-BR", "zh-Hans" or "sr-Latn-RS".
+// Tags are case-insensitive, they are returned in canonical case: "zh-Hans-CN".
+package langtag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag is a parsed BCP-47 language tag.
+type Tag struct {
+	Language   string   // primary language subtag with extended ones, like "zh" or "zh-yue"
+	Script     string   // four letters script subtag, like "Latn"
+	Region     string   // two letters or three digits region subtag, like "BR" or "419"
+	Variants   []string // registered variants, like "1901"
+	Extensions string   // extensions and private use subtags, like "u-ca-buddhist" or "x-private"
+}
+
+// Parse parses a BCP-47 language tag, subtags can be separated by hyphens or underscores.
+func Parse(s string) (*Tag, error) {
+	subtags := strings.FieldsFunc(strings.ToLower(strings.TrimSpace(s)), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+
+	if len(subtags) == 0 || strings.Count(s, "-")+strings.Count(s, "_") != len(subtags)-1 {
+		return nil, fmt.Errorf("invalid language tag %q: empty subtag", s)
+	}
+
+	language := subtags[0]
+	if n := len(language); n < 2 || n > 8 || !isAlpha(language) {
+		return nil, fmt.Errorf("invalid language tag %q: bad language subtag %q", s, language)
+	}
+
+	tag := &Tag{Language: language}
+	i := 1
+
+	// up to three extended language subtags are allowed only after two or three letters language
+	for ; i < len(subtags) && i <= 3 && len(language) <= 3 && len(subtags[i]) == 3 && isAlpha(subtags[i]); i++ {
+		tag.Language += "-" + subtags[i]
+	}
+
+	if i < len(subtags) && len(subtags[i]) == 4 && isAlpha(subtags[i]) {
+		tag.Script = strings.ToUpper(subtags[i][:1]) + subtags[i][1:]
+		i++
+	}
+
+	if i < len(subtags) && isRegion(subtags[i]) {
+		tag.Region = strings.ToUpper(subtags[i])
+		i++
+	}
+
+	for ; i < len(subtags) && isVariant(subtags[i]); i++ {
+		tag.Variants = append(tag.Variants, subtags[i])
+	}
+
+	if i < len(subtags) {
+		if err := checkExtensions(subtags[i:]); err != nil {
+			return nil, fmt.Errorf("invalid language tag %q: %w", s, err)
+		}
+		tag.Extensions = strings.Join(subtags[i:], "-")
+	}
+
+	return tag, nil
+}
+
+// String returns a tag in canonical case.
+func (t *Tag) String() string {
+	subtags := []string{t.Language}
+	for _, subtag := range append([]string{t.Script, t.Region}, append(t.Variants, t.Extensions)...) {
+		if subtag != "" {
+			subtags = append(subtags, subtag)
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+// Base returns a primary language subtag.
+func (t *Tag) Base() string {
+	base, _, _ := strings.Cut(t.Language, "-")
+	return base
+}
+
+// Fallbacks returns the tag and its truncated forms from the most specific to the primary language,
+// like "zh-Hans-CN", "zh-Hans", "zh" (lookup algorithm of RFC 4647).
+func (t *Tag) Fallbacks() []string {
+	var (
+		subtags = strings.Split(t.String(), "-")
+		result  = make([]string, 0, len(subtags))
+	)
+
+	for n := len(subtags); n > 0; n-- {
+		if len(subtags[n-1]) == 1 {
+			continue // singletons of extensions are removed with their subtags
+		}
+		result = append(result, strings.Join(subtags[:n], "-"))
+	}
+
+	return result
+}
+
+// Canonical returns a well-formed tag in canonical case or the lower case string if it is not a tag.
+func Canonical(s string) string {
+	if tag, err := Parse(s); err == nil {
+		return tag.String()
+	}
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Base returns a primary language subtag of a tag, like "sr" for "sr-Latn".
+func Base(s string) string {
+	if tag, err := Parse(s); err == nil {
+		return tag.Base()
+	}
+
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "-")
+	return base
+}
+
+// checkExtensions validates extensions and private use subtags.
+func checkExtensions(subtags []string) error {
+	for i := 0; i < len(subtags); {
+		singleton := subtags[i]
+		if len(singleton) != 1 || !isAlphaNum(singleton) {
+			return fmt.Errorf("unexpected subtag %q", singleton)
+		}
+
+		minLength, j := 2, i+1
+		if singleton == "x" {
+			minLength, j = 1, len(subtags) // private use subtags are the last ones
+		} else {
+			for j < len(subtags) && len(subtags[j]) > 1 {
+				j++
+			}
+		}
+
+		if j == i+1 {
+			return fmt.Errorf("empty extension %q", singleton)
+		}
+
+		for _, subtag := range subtags[i+1 : j] {
+			if n := len(subtag); n < minLength || n > 8 || !isAlphaNum(subtag) {
+				return fmt.Errorf("bad extension subtag %q", subtag)
+			}
+		}
+
+		i = j
+	}
+
+	return nil
+}
+
+// isRegion returns true for two letters or three digits region subtag.
+func isRegion(s string) bool {
+	return (len(s) == 2 && isAlpha(s)) || (len(s) == 3 && isDigit(s))
+}
+
+// isVariant returns true for 5-8 alphanumeric characters or a digit with three alphanumeric ones.
+func isVariant(s string) bool {
+	n := len(s)
+	return isAlphaNum(s) && ((n >= 5 && n <= 8) || (n == 4 && isDigit(s[:1])))
+}
+
+func isAlpha(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r < 'a' || r > 'z' }) < 0
+}
+
+func isDigit(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
+
+func isAlphaNum(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return (r < 'a' || r > 'z') && (r < '0' || r > '9') }) < 0
+}
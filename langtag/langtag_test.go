@@ -0,0 +1,87 @@
+package langtag
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		tag       string
+		expected  string
+		base      string
+		fallbacks []string
+		err       string
+	}{
+		{tag: "en", expected: "en", base: "en", fallbacks: []string{"en"}},
+		{tag: " EN ", expected: "en", base: "en", fallbacks: []string{"en"}},
+		{tag: "pt-br", expected: "pt-BR", base: "pt", fallbacks: []string{"pt-BR", "pt"}},
+		{tag: "zh_hans", expected: "zh-Hans", base: "zh", fallbacks: []string{"zh-Hans", "zh"}},
+		{tag: "sr-latn-rs", expected: "sr-Latn-RS", base: "sr", fallbacks: []string{"sr-Latn-RS", "sr-Latn", "sr"}},
+		{tag: "es-419", expected: "es-419", base: "es", fallbacks: []string{"es-419", "es"}},
+		{tag: "zh-yue-HK", expected: "zh-yue-HK", base: "zh", fallbacks: []string{"zh-yue-HK", "zh-yue", "zh"}},
+		{tag: "de-DE-1901", expected: "de-DE-1901", base: "de", fallbacks: []string{"de-DE-1901", "de-DE", "de"}},
+		{tag: "kazlat", expected: "kazlat", base: "kazlat", fallbacks: []string{"kazlat"}},
+		{
+			tag:       "th-TH-u-nu-thai-x-Private",
+			expected:  "th-TH-u-nu-thai-x-private",
+			base:      "th",
+			fallbacks: []string{"th-TH-u-nu-thai-x-private", "th-TH-u-nu-thai", "th-TH-u-nu", "th-TH", "th"},
+		},
+		{tag: "", err: `invalid language tag "": empty subtag`},
+		{tag: "en--US", err: `invalid language tag "en--US": empty subtag`},
+		{tag: "e", err: `invalid language tag "e": bad language subtag "e"`},
+		{tag: "en1", err: `invalid language tag "en1": bad language subtag "en1"`},
+		{tag: "zh-Hans-Latn", err: `invalid language tag "zh-Hans-Latn": unexpected subtag "latn"`},
+		{tag: "en-US-u", err: `invalid language tag "en-US-u": empty extension "u"`},
+		{tag: "en-a-b", err: `invalid language tag "en-a-b": empty extension "a"`},
+	}
+
+	for _, tc := range testCases {
+		tag, err := Parse(tc.tag)
+		if err != nil {
+			if e := err.Error(); e != tc.err {
+				t.Errorf("%q: expected error %q, got %q", tc.tag, tc.err, e)
+			}
+			continue
+		}
+
+		if tc.err != "" {
+			t.Errorf("%q: expected error %q", tc.tag, tc.err)
+			continue
+		}
+
+		if s := tag.String(); s != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.tag, tc.expected, s)
+		}
+
+		if b := tag.Base(); b != tc.base {
+			t.Errorf("%q: expected base %q, got %q", tc.tag, tc.base, b)
+		}
+
+		if f := tag.Fallbacks(); !slices.Equal(f, tc.fallbacks) {
+			t.Errorf("%q: expected fallbacks %v, got %v", tc.tag, tc.fallbacks, f)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	testCases := []struct {
+		s, canonical, base string
+	}{
+		{s: "SR-LATN", canonical: "sr-Latn", base: "sr"},
+		{s: "English", canonical: "english", base: "english"},
+		{s: "Chinese (Simplified)", canonical: "chinese (simplified)", base: "chinese (simplified)"},
+		{s: "zh-Hans-Latn", canonical: "zh-hans-latn", base: "zh"},
+	}
+
+	for _, tc := range testCases {
+		if c := Canonical(tc.s); c != tc.canonical {
+			t.Errorf("%q: expected %q, got %q", tc.s, tc.canonical, c)
+		}
+
+		if b := Base(tc.s); b != tc.base {
+			t.Errorf("%q: expected base %q, got %q", tc.s, tc.base, b)
+		}
+	}
+}
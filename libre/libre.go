@@ -0,0 +1,163 @@
+// Package libre implements a client of LibreTranslate compatible HTTP API.
+// Documentation https://docs.libretranslate.com/guides/api_usage/
+package libre
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// API paths of LibreTranslate server.
+const (
+	TranslatePath = "/translate"
+	DetectPath    = "/detect"
+	LanguagesPath = "/languages"
+)
+
+// Formats of translated texts.
+const (
+	formatText = "text"
+	formatHTML = "html"
+)
+
+// Request is a type of translation request.
+type Request struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+// Response is a type of translation response for several texts.
+type Response struct {
+	TranslatedText []string `json:"translatedText"`
+}
+
+// DetectRequest is a type of detect language request.
+type DetectRequest struct {
+	Q      string `json:"q"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// Detection is a detected language with its confidence.
+type Detection struct {
+	Confidence float64 `json:"confidence"`
+	Language   string  `json:"language"`
+}
+
+// Language is a supported language with its available targets.
+type Language struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Targets []string `json:"targets"`
+}
+
+// endpoint returns a full URL of API path.
+func endpoint(cfg *config.Config, path string) string {
+	return cfg.GetURL(strings.TrimRight(cfg.LibreTranslate.URL, "/") + path)
+}
+
+// call does JSON POST request or GET one if data is nil.
+func call(ctx context.Context, client *http.Client, cfg *config.Config, path string, data any) ([]byte, error) {
+	if cfg.LibreTranslate.URL == "" {
+		return nil, fmt.Errorf("LibreTranslate URL is not configured")
+	}
+
+	if data == nil {
+		return cloud.Request(ctx, client, nil, endpoint(cfg, path), "", cfg.UserAgent, true, cfg.Logger)
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return cloud.Request(ctx, client, bytes.NewReader(body), endpoint(cfg, path), "", cfg.UserAgent, true, cfg.Logger)
+}
+
+// Translate returns translated texts, the request format is HTML or plain text.
+func Translate(ctx context.Context, client *http.Client, cfg *config.Config, r *translation.Request) (*translation.Response, error) {
+	request := &Request{
+		Q:      r.Texts,
+		Source: r.SourceLanguageCode,
+		Target: r.TargetLanguageCode,
+		Format: formatText,
+		APIKey: cfg.LibreTranslate.APIKey,
+	}
+
+	if r.Format == translation.FormatHTML {
+		request.Format = formatHTML
+	}
+
+	body, err := call(ctx, client, cfg, TranslatePath, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate: %w", err)
+	}
+
+	response := &Response{}
+	if err = json.Unmarshal(body, response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal translation response: %w", err)
+	}
+
+	result := &translation.Response{Translations: make([]translation.ResponseItem, len(response.TranslatedText))}
+	for i, text := range response.TranslatedText {
+		result.Translations[i] = translation.ResponseItem{Text: text}
+	}
+
+	return result, nil
+}
+
+// DetectLanguage returns the most confident detected language.
+func DetectLanguage(ctx context.Context, client *http.Client, cfg *config.Config, text string) (string, error) {
+	body, err := call(ctx, client, cfg, DetectPath, &DetectRequest{Q: text, APIKey: cfg.LibreTranslate.APIKey})
+	if err != nil {
+		return "", fmt.Errorf("failed to detect language: %w", err)
+	}
+
+	var detections []Detection
+	if err = json.Unmarshal(body, &detections); err != nil {
+		return "", fmt.Errorf("failed to unmarshal detect language response: %w", err)
+	}
+
+	var best *Detection
+	for i := range detections {
+		if best == nil || detections[i].Confidence > best.Confidence {
+			best = &detections[i]
+		}
+	}
+
+	if best == nil || best.Language == "" {
+		return "", fmt.Errorf("language is not detected")
+	}
+
+	return best.Language, nil
+}
+
+// LoadLanguages loads supported languages.
+func LoadLanguages(ctx context.Context, client *http.Client, cfg *config.Config) (*translation.Languages, error) {
+	body, err := call(ctx, client, cfg, LanguagesPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get translation languages: %w", err)
+	}
+
+	var languages []Language
+	if err = json.Unmarshal(body, &languages); err != nil {
+		return nil, fmt.Errorf("failed to decode translation languages response: %w", err)
+	}
+
+	items := make([]translation.Language, len(languages))
+	for i, lg := range languages {
+		items[i] = translation.Language{Code: lg.Code, Name: lg.Name}
+	}
+
+	return translation.NewLanguages(items), nil
+}
@@ -0,0 +1,165 @@
+package libre
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+var logger = log.New(os.Stdout, "TEST ", log.Lmicroseconds|log.Lshortfile)
+
+func testServer(t *testing.T, apiKey string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == LanguagesPath {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			response := `[{"code":"en","name":"English","targets":["ru"]},{"code":"ru","name":"Russian","targets":["en"]}]`
+			if _, err := fmt.Fprint(w, response); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		var request struct {
+			Q      any    `json:"q"`
+			Format string `json:"format"`
+			APIKey string `json:"api_key"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if request.APIKey != apiKey {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var response any
+		switch r.URL.Path {
+		case TranslatePath:
+			texts, ok := request.Q.([]any)
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			items := make([]string, len(texts))
+			for i, text := range texts {
+				items[i] = fmt.Sprintf("%s:%v", request.Format, text)
+			}
+			response = Response{TranslatedText: items}
+		case DetectPath:
+			response = []Detection{{Confidence: 20, Language: "de"}, {Confidence: 90, Language: "en"}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestTranslate(t *testing.T) {
+	s := testServer(t, "key")
+	defer s.Close()
+
+	cfg := &config.Config{LibreTranslate: config.LibreTranslate{URL: s.URL + "/", APIKey: "key"}, Logger: logger}
+	testCases := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "text", expected: "text:time to start\ntext:go"},
+		{name: "html", format: translation.FormatHTML, expected: "html:time to start\nhtml:go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &translation.Request{
+				Texts:              []string{"time to start", "go"},
+				SourceLanguageCode: "en",
+				TargetLanguageCode: "ru",
+				Format:             tc.format,
+			}
+
+			resp, err := Translate(context.Background(), s.Client(), cfg, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rs := resp.String(); rs != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, rs)
+			}
+		})
+	}
+}
+
+func TestTranslate_Error(t *testing.T) {
+	s := testServer(t, "key")
+	defer s.Close()
+
+	req := &translation.Request{Texts: []string{"go"}, SourceLanguageCode: "en", TargetLanguageCode: "ru"}
+
+	cfg := &config.Config{LibreTranslate: config.LibreTranslate{URL: s.URL}, Logger: logger}
+	if _, err := Translate(context.Background(), s.Client(), cfg, req); err == nil {
+		t.Error("expected error for wrong API key")
+	}
+
+	cfg.LibreTranslate.URL = ""
+	_, err := Translate(context.Background(), s.Client(), cfg, req)
+	if err == nil || !strings.HasSuffix(err.Error(), "LibreTranslate URL is not configured") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	s := testServer(t, "")
+	defer s.Close()
+
+	cfg := &config.Config{LibreTranslate: config.LibreTranslate{URL: s.URL}, Logger: logger}
+	lang, err := DetectLanguage(context.Background(), s.Client(), cfg, "time to start")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lang != "en" {
+		t.Errorf("expected %q, got %q", "en", lang)
+	}
+}
+
+func TestLoadLanguages(t *testing.T) {
+	s := testServer(t, "")
+	defer s.Close()
+
+	cfg := &config.Config{LibreTranslate: config.LibreTranslate{URL: s.URL}, Logger: logger}
+	languages, err := LoadLanguages(context.Background(), s.Client(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := languages.Len(); n != 2 {
+		t.Errorf("expected 2 languages, got %d", n)
+	}
+
+	if !languages.Contains("en", "ru") {
+		t.Error("direction en-ru is not found")
+	}
+}
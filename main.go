@@ -15,6 +15,7 @@ import (
 	"github.com/z0rr0/ytapigo/arguments"
 	"github.com/z0rr0/ytapigo/config"
 	"github.com/z0rr0/ytapigo/handle"
+	"github.com/z0rr0/ytapigo/subtitle"
 )
 
 // Name is a program name.
@@ -39,6 +40,15 @@ func main() {
 		version   bool
 		noCache   bool
 		direction string
+		input     string
+		output    string
+		include   string
+		flags     string
+		pos       string
+		dict      bool
+		translate bool
+		exclude   string
+		options   = handle.Options{LineWidth: subtitle.DefaultWidth, Name: Name, Version: Version}
 		timeout   = 5 * time.Second
 		start     = time.Now()
 	)
@@ -63,16 +73,55 @@ func main() {
 	flag.BoolVar(&debug, "d", false, "debug mode")
 	flag.BoolVar(&version, "v", false, "print version")
 	flag.StringVar(&configFile, "c", configFile, "configuration file")
-	flag.BoolVar(&noCache, "r", false, "reset cache")
+	flag.BoolVar(&noCache, "r", false, "reset cache: ignore cached token, languages lists and providers failures and update them")
 	flag.DurationVar(&timeout, "t", timeout, "timeout for requests")
+	flag.StringVar(&input, "f", "", "input file to translate (markdown, srt/vtt subtitles, po/pot catalog, xliff, json/yaml bundle or plain text), long texts are split by paragraphs and sentences")
+	flag.StringVar(&output, "o", "", "output file for translated input file (empty - stdout)")
+	flag.BoolVar(&options.Force, "force", false, "translate again already translated messages of gettext catalogs and values of JSON/YAML bundles")
+	flag.StringVar(&include, "include", "", "comma separated key path patterns of translated JSON/YAML values, like 'errors.*'")
+	flag.StringVar(&exclude, "exclude", "", "comma separated key path patterns of skipped JSON/YAML values")
+	flag.BoolVar(&options.Back, "b", false, "round-trip mode: translate text back to the source language and compare with the original")
+	flag.BoolVar(&options.Explain, "e", false, "explain mode: translate text and gloss its content words by dictionary transcriptions and primary translations")
+	flag.BoolVar(&options.Protect, "p", false, "protect placeholders (format verbs, braces, HTML tags and entities) of texts and plain files, they are always protected for gettext catalogs and JSON/YAML bundles")
+	flag.StringVar(&options.Format, "format", handle.FormatTable, "output format of languages command: table, json or codes")
+	flag.BoolVar(&dict, "dict", false, "dictionary mode: look up any text in the dictionary, like idioms 'give up'")
+	flag.BoolVar(&translate, "translate", false, "translation mode: translate any text by translation API, like single words")
+	flag.StringVar(&options.UI, "ui", "", "dictionary language of parts of speech names and notes, like 'en'")
+	flag.StringVar(&flags, "flags", "", "comma separated dictionary lookup flags: family, short_pos, morpho (search by word forms like 'went'), pos_filter")
+	flag.StringVar(&pos, "pos", "", "comma separated shown parts of speech of dictionary articles or their prefixes, like 'noun,adj'")
+	flag.IntVar(&options.View.MaxTr, "max-tr", 0, "max translations of dictionary article (0 - no limit)")
+	flag.IntVar(&options.View.MaxSyn, "max-syn", 0, "max synonyms of dictionary translation (0 - no limit)")
+	flag.BoolVar(&options.View.SortSyn, "fr", false, "sort dictionary synonyms by frequency")
+	flag.BoolVar(&options.View.NoExamples, "no-ex", false, "hide dictionary examples")
+	flag.BoolVar(&options.View.NoMeanings, "no-mean", false, "hide dictionary meanings")
+	flag.BoolVar(&options.View.Compact, "compact", false, "compact dictionary output: one line per translation with its synonyms and meanings")
+	flag.BoolVar(&options.Reverse, "reverse", false, "thesaurus command: look up synonyms of every found synonym")
+	flag.IntVar(&options.LineWidth, "w", options.LineWidth, "max line width of translated subtitles (0 - no wrapping)")
 	flag.StringVar(
 		&direction, "g", "",
 		fmt.Sprintf("translation direction "+
-			"(empty - 'en-ru' or 'ru-en' by ASCII codes, %q - auto-detected language to ru)", handle.AutoLanguageDetect,
+			"(empty - 'en-ru' or 'ru-en' by ASCII codes, %q - auto-detected language to ru, "+
+			"'english-german' - languages names, 'en:zh-Hans' or 'en->sr-Latn' - BCP-47 tags, 'en-ru,de,fr' - several target languages)", handle.AutoLanguageDetect,
 		),
 	)
 
 	flag.Parse()
+	options.Include, options.Exclude = arguments.Patterns(include), arguments.Patterns(exclude)
+	options.Flags, options.View.Pos = arguments.Patterns(flags), arguments.Patterns(pos)
+
+	switch {
+	case dict && translate:
+		panic("dictionary and translation modes can not be used together")
+	case dict && options.Back:
+		panic("dictionary and round-trip modes can not be used together")
+	case options.Explain && (options.Back || dict):
+		panic("explain mode can not be used together with round-trip or dictionary modes")
+	case dict:
+		options.Mode = handle.ModeDictionary
+	case translate:
+		options.Mode = handle.ModeTranslate
+	}
+
 	if version {
 		fmt.Printf("%v: %v %v %v %v\n", Name, Version, Revision, GoVersion, BuildDate)
 		flag.PrintDefaults()
@@ -88,15 +137,47 @@ func main() {
 		"\n\tCONFIG:\t%v\n\tKEY:\t%v\n\tCACHE:\t%v", configFile, cfg.Translation.KeyFile, cfg.AuthCache,
 	)
 
-	params, err := arguments.Build(flag.Args(), os.Stdin)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	y, err := handle.New(cfg, options)
 	if err != nil {
 		panic(err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	if input != "" {
+		if err = y.RunFile(ctx, direction, input, output); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if args := flag.Args(); len(args) > 0 && args[0] == handle.MemoryCommand {
+		if err = y.RunMemory(direction, args[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if args := flag.Args(); len(args) > 0 && args[0] == handle.LanguagesCommand {
+		if err = y.RunLanguages(ctx, args[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if args := flag.Args(); len(args) > 0 && args[0] == handle.ThesaurusCommand {
+		if err = y.RunThesaurus(ctx, direction, args[1:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	params, err := arguments.Build(flag.Args(), os.Stdin)
+	if err != nil {
+		panic(err)
+	}
 
-	y := handle.New(cfg)
 	if err = y.Run(ctx, direction, params); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,166 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/markup"
+)
+
+var (
+	autoLinkRegexp = regexp.MustCompile(`^<(?:[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[^\s@<>]+@[^\s@<>]+)>`)
+	htmlTagRegexp  = regexp.MustCompile(`^(?:<!--.*?-->|</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>)`)
+	urlRegexp      = regexp.MustCompile(`^(?:https?://|www\.)[^\s<>]*[^\s<>.,;:!?'")\]]`)
+)
+
+// closing returns an index of the closing bracket or parenthesis for the opening one at i position.
+func closing(text string, i int, open, close byte) int {
+	depth := 0
+
+	for j := i; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '`':
+			if end := codeSpanEnd(text, j); end > 0 {
+				j = end - 1
+			}
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+
+	return -1
+}
+
+// codeSpanEnd returns the end position of the code span starting at i, or -1 if it is not closed.
+func codeSpanEnd(text string, i int) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == '`' {
+		n++
+	}
+
+	fence := text[i : i+n]
+	for j := i + n; j < len(text); {
+		k := strings.Index(text[j:], fence)
+		if k < 0 {
+			return -1
+		}
+
+		k += j
+		end := k + n
+		if end == len(text) || text[end] != '`' {
+			return end
+		}
+
+		for end < len(text) && text[end] == '`' {
+			end++
+		}
+		j = end
+	}
+
+	return -1
+}
+
+// destination returns a link destination like "(url)" or "[ref]" which starts at i position.
+func destination(text string, i int) string {
+	if i >= len(text) {
+		return ""
+	}
+
+	var end int
+	switch text[i] {
+	case '(':
+		end = closing(text, i, '(', ')')
+	case '[':
+		end = closing(text, i, '[', ']')
+	default:
+		return ""
+	}
+
+	if end < 0 {
+		return ""
+	}
+
+	return text[i : end+1]
+}
+
+// mask converts markdown inline text to HTML with placeholders.
+// Code spans, URLs, images and HTML tags are protected, link texts are translated.
+func mask(m *markup.Text, text string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		rest := text[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(text):
+			b.WriteString(markup.Escape(text[i : i+2]))
+			i += 2
+			continue
+		case c == '`':
+			if end := codeSpanEnd(text, i); end > 0 {
+				b.WriteString(m.Token(text[i:end]))
+				i = end
+				continue
+			}
+		case c == '<':
+			if loc := autoLinkRegexp.FindStringIndex(rest); loc != nil {
+				b.WriteString(m.Token(rest[:loc[1]]))
+				i += loc[1]
+				continue
+			}
+			if loc := htmlTagRegexp.FindStringIndex(rest); loc != nil {
+				b.WriteString(m.Token(rest[:loc[1]]))
+				i += loc[1]
+				continue
+			}
+		case c == '\n' || strings.HasPrefix(rest, "\r\n"):
+			// soft line break of a paragraph, spaces around it are removed after translation
+			brk := rest[:softBreakRegexp.FindStringIndex(rest)[1]]
+			b.WriteString(" " + m.Token(brk))
+			i += len(brk)
+			continue
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if end := closing(text, i+1, '[', ']'); end > 0 {
+				end += len(destination(text, end+1)) + 1
+				b.WriteString(m.Token(text[i:end]))
+				i = end
+				continue
+			}
+		case c == '[':
+			if end := closing(text, i, '[', ']'); end > 0 {
+				if dst := destination(text, end+1); dst != "" {
+					open, closed := m.Pair("[", "]"+dst)
+					b.WriteString(open + mask(m, text[i+1:end]) + closed)
+					i = end + 1 + len(dst)
+					continue
+				}
+			}
+		case c == 'h' || c == 'w':
+			if i == 0 || !isWordByte(text[i-1]) {
+				if loc := urlRegexp.FindStringIndex(rest); loc != nil {
+					b.WriteString(m.Token(rest[:loc[1]]))
+					i += loc[1]
+					continue
+				}
+			}
+		}
+
+		b.WriteString(markup.Escape(text[i : i+1]))
+		i++
+	}
+
+	return b.String()
+}
+
+// isWordByte returns true if c is an ASCII letter or digit.
+func isWordByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,363 @@
+// Package markdown implements translation of Markdown documents.
+// Only prose is translated: headings, paragraphs, list items, table cells and link texts.
+// Code blocks and spans, URLs, front matter and HTML blocks are kept as is.
+package markdown
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/markup"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+var (
+	fenceRegexp     = regexp.MustCompile("^\\s*(`{3,}|~{3,})")
+	htmlBlockRegexp = regexp.MustCompile(`^ {0,3}(?:<!--|<\?|<![A-Za-z]|</?[A-Za-z][A-Za-z0-9-]*(?:\s|/?>|$))`)
+	referenceRegexp = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*\S`)
+	breakRegexp     = regexp.MustCompile(`^ {0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,}|=+\s*)$`)
+	delimiterRegexp = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	headingRegexp   = regexp.MustCompile(`^( {0,3}#{1,6}[ \t]+)(.*?)([ \t]+#+)?[ \t]*$`)
+	quoteRegexp     = regexp.MustCompile(`^(?: {0,3}>[ \t]?)+`)
+	listRegexp      = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])(?:[ \t]+\[[ xX]\])?[ \t]+`)
+	indentRegexp    = regexp.MustCompile(`^(?: {4}|\t)`)
+	softBreakRegexp = regexp.MustCompile(`\r?\n(?: {0,3}>[ \t]?)*[ \t]*`)
+)
+
+// part is a document part, it is translatable if text is not empty.
+type part struct {
+	raw    string // protected text
+	prefix string // block markers before translatable text
+	text   string // translatable text
+	suffix string // heading closing sequence, hard line break and end of line
+	quote  string // blockquote markers, a paragraph continuation line must have the same ones
+	open   bool   // paragraph can be continued by the next line
+}
+
+// document is a parsed Markdown document.
+type document struct {
+	parts []*part
+}
+
+// addRaw adds a protected text.
+func (d *document) addRaw(text string) {
+	if text != "" {
+		d.parts = append(d.parts, &part{raw: text})
+	}
+}
+
+// last returns the last part if it is an open paragraph.
+func (d *document) last() *part {
+	if n := len(d.parts); n > 0 && d.parts[n-1].open {
+		return d.parts[n-1]
+	}
+	return nil
+}
+
+// addText adds a translatable text line or continues an open paragraph.
+func (d *document) addText(prefix, quote, text, eol string, open bool) {
+	body := strings.TrimRight(text, " \t")
+	suffix := text[len(body):]
+
+	if strings.HasSuffix(suffix, "  ") || strings.HasSuffix(body, "\\") {
+		open = false // hard line break
+	} else {
+		suffix = ""
+	}
+
+	if body == "" {
+		d.closeParagraph() // empty line of a blockquote or a list item finishes the paragraph
+		d.addRaw(prefix + text + eol)
+		return
+	}
+
+	if p := d.last(); p != nil && prefix == quote && p.quote == quote {
+		// soft line break is kept with markers of the continuation line, the suffix is an end of line
+		p.text += p.suffix + prefix + body
+		p.suffix = suffix + eol
+		p.open = open
+		return
+	}
+
+	d.parts = append(d.parts, &part{prefix: prefix, text: body, suffix: suffix + eol, quote: quote, open: open})
+}
+
+// closeParagraph prevents continuation of the last paragraph.
+func (d *document) closeParagraph() {
+	if p := d.last(); p != nil {
+		p.open = false
+	}
+}
+
+// splitLine returns a line without end of line and the end of line itself.
+func splitLine(line string) (string, string) {
+	text := strings.TrimRight(line, "\r\n")
+	return text, line[len(text):]
+}
+
+// lines splits data to lines keeping their ends.
+func lines(data string) []string {
+	result := strings.SplitAfter(data, "\n")
+	if n := len(result); n > 0 && result[n-1] == "" {
+		result = result[:n-1]
+	}
+	return result
+}
+
+// frontMatter returns a number of front matter lines.
+func frontMatter(items []string) int {
+	if len(items) == 0 {
+		return 0
+	}
+
+	marker, _ := splitLine(items[0])
+	if marker != "---" && marker != "+++" {
+		return 0
+	}
+
+	for i := 1; i < len(items); i++ {
+		if line, _ := splitLine(items[i]); line == marker || (marker == "---" && line == "...") {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// fenceEnd returns an index of the line after the fenced code block which starts at i.
+func fenceEnd(items []string, i int) int {
+	line, _ := splitLine(items[i])
+	fence := fenceRegexp.FindStringSubmatch(line)[1]
+
+	for j := i + 1; j < len(items); j++ {
+		line, _ = splitLine(items[j])
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == "" {
+			return j + 1
+		}
+	}
+
+	return len(items)
+}
+
+// htmlBlockEnd returns an index of the line after HTML block which starts at i.
+func htmlBlockEnd(items []string, i int) int {
+	line, _ := splitLine(items[i])
+	isComment := strings.HasPrefix(strings.TrimSpace(line), "<!--")
+
+	for j := i; j < len(items); j++ {
+		line, _ = splitLine(items[j])
+
+		if isComment {
+			if strings.Contains(line, "-->") {
+				return j + 1
+			}
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			return j
+		}
+	}
+
+	return len(items)
+}
+
+// isTableRow returns true if the line can be a table row.
+func isTableRow(line string) bool {
+	return strings.Contains(line, "|") && strings.TrimSpace(line) != ""
+}
+
+// addTableRow adds table row cells as translatable texts.
+func (d *document) addTableRow(line, eol string) {
+	start := 0
+	cells := make([]string, 0)
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			if end := codeSpanEnd(line, i); end > 0 {
+				i = end - 1
+			}
+		case '|':
+			cells = append(cells, line[start:i], "|")
+			start = i + 1
+		}
+	}
+	cells = append(cells, line[start:])
+
+	for _, cell := range cells {
+		text := strings.TrimSpace(cell)
+		if text == "" || text == "|" {
+			d.addRaw(cell)
+			continue
+		}
+
+		left := cell[:strings.Index(cell, text)]
+		d.addRaw(left)
+		d.parts = append(d.parts, &part{text: text, suffix: cell[len(left)+len(text):]})
+	}
+	d.addRaw(eol)
+}
+
+// parse splits Markdown data to protected and translatable parts.
+func parse(data string) *document {
+	var (
+		d      = &document{}
+		items  = lines(data)
+		inList bool
+	)
+
+	i := frontMatter(items)
+	d.addRaw(strings.Join(items[:i], ""))
+
+	for i < len(items) {
+		line, eol := splitLine(items[i])
+
+		if line != "" && line[0] != ' ' && line[0] != '\t' && !listRegexp.MatchString(line) {
+			inList = false // not indented block finishes a list
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			d.closeParagraph()
+			d.addRaw(items[i])
+			i++
+			continue
+		case fenceRegexp.MatchString(line):
+			end := fenceEnd(items, i)
+			d.closeParagraph()
+			d.addRaw(strings.Join(items[i:end], ""))
+			i = end
+			continue
+		case htmlBlockRegexp.MatchString(line):
+			end := max(htmlBlockEnd(items, i), i+1)
+			d.closeParagraph()
+			d.addRaw(strings.Join(items[i:end], ""))
+			i = end
+			continue
+		case d.last() == nil && !inList && indentRegexp.MatchString(line):
+			d.addRaw(items[i]) // indented code block
+			i++
+			continue
+		case referenceRegexp.MatchString(line) || breakRegexp.MatchString(line):
+			d.closeParagraph()
+			d.addRaw(items[i])
+			i++
+			continue
+		case isTableRow(line) && i+1 < len(items) && delimiterRegexp.MatchString(strings.TrimRight(items[i+1], "\r\n")):
+			d.closeParagraph()
+			d.addTableRow(line, eol)
+			d.addRaw(items[i+1])
+
+			for i += 2; i < len(items); i++ {
+				line, eol = splitLine(items[i])
+				if !isTableRow(line) {
+					break
+				}
+				d.addTableRow(line, eol)
+			}
+			continue
+		}
+
+		quote := quoteRegexp.FindString(line)
+		rest := line[len(quote):]
+
+		if m := headingRegexp.FindStringSubmatch(rest); m != nil {
+			d.closeParagraph()
+			d.parts = append(d.parts, &part{prefix: quote + m[1], text: m[2], suffix: m[3] + eol})
+			i++
+			continue
+		}
+
+		prefix := quote
+		if marker := listRegexp.FindString(rest); marker != "" {
+			d.closeParagraph()
+			inList = true
+			prefix += marker
+			rest = rest[len(marker):]
+		} else if inList && quote == "" && d.last() == nil {
+			inList = strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, "\t")
+			indent := len(rest) - len(strings.TrimLeft(rest, " \t"))
+			prefix += rest[:indent]
+			rest = rest[indent:]
+		}
+
+		d.addText(prefix, quote, rest, eol, true)
+		i++
+	}
+
+	return d
+}
+
+// keepBreaks removes spaces which are added around soft line breaks of the source paragraph by translation.
+// Longer breaks are matched first, so indentation of continuation lines is kept.
+func keepBreaks(text, source string) string {
+	breaks := softBreakRegexp.FindAllString(source, -1)
+	if len(breaks) == 0 {
+		return text
+	}
+
+	slices.SortFunc(breaks, func(a, b string) int { return cmp.Or(len(b)-len(a), strings.Compare(a, b)) })
+	for i, brk := range breaks {
+		breaks[i] = regexp.QuoteMeta(brk)
+	}
+
+	re := regexp.MustCompile(`[ \t]*(` + strings.Join(slices.Compact(breaks), "|") + `)[ \t]*`)
+	return re.ReplaceAllString(text, "$1")
+}
+
+// Translate translates Markdown document data by HTML translation function fn.
+func Translate(ctx context.Context, data []byte, fn translation.BatchFunc) ([]byte, error) {
+	var (
+		d       = parse(string(data))
+		texts   []string
+		inlines []*markup.Text
+		parts   []*part
+	)
+
+	for _, p := range d.parts {
+		if p.text == "" {
+			continue
+		}
+
+		m := &markup.Text{}
+		texts = append(texts, mask(m, p.text))
+		inlines = append(inlines, m)
+		parts = append(parts, p)
+	}
+
+	translations, err := fn(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(translations) != len(texts) {
+		return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(texts))
+	}
+
+	for i, p := range parts {
+		text, err := inlines[i].Restore(translations[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed markdown text %q: %w", p.text, err)
+		}
+		p.text = keepBreaks(text, p.text)
+	}
+
+	var b strings.Builder
+	for _, p := range d.parts {
+		b.WriteString(p.raw)
+		b.WriteString(p.prefix)
+		b.WriteString(p.text)
+		b.WriteString(p.suffix)
+	}
+
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,167 @@
+package markdown
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/internal/testfn"
+)
+
+var tagRegexp = regexp.MustCompile(`<[^<>]*>`)
+
+// same is a fake translation function which returns texts as is.
+func same(_ context.Context, texts []string) ([]string, error) {
+	return texts, nil
+}
+
+// lost is a fake translation function which removes all tags.
+func lost(_ context.Context, texts []string) ([]string, error) {
+	result := make([]string, len(texts))
+	for i, text := range texts {
+		result[i] = tagRegexp.ReplaceAllString(text, "")
+	}
+	return result, nil
+}
+
+const source = `---
+title: front matter
+---
+# Heading with ` + "`code`" + ` #
+
+Some paragraph with [link text](https://example.com/path "title") and
+continuation line, see https://github.com/z0rr0/ytapigo.
+Escaped \[not a link\] & <b>tag</b> ![image alt](img.png).
+
+* list item **bold**
+  item continuation
+1. [ ] numbered task
+   > quote
+
+> Quote line
+> continued
+
+` + "```go" + `
+fmt.Println("code")
+` + "```" + `
+
+    indented code
+
+<div>
+html block
+</div>
+
+| Name | Value |
+|------|:-----:|
+| one  | ` + "`1`" + ` |
+
+[ref]: https://example.com
+Text with [reference][ref] and <https://auto.link>.
+`
+
+const expected = `---
+title: front matter
+---
+# HEADING WITH ` + "`code`" + ` #
+
+SOME PARAGRAPH WITH [LINK TEXT](https://example.com/path "title") AND
+CONTINUATION LINE, SEE https://github.com/z0rr0/ytapigo.
+ESCAPED \[NOT A LINK\] & <b>TAG</b> ![image alt](img.png).
+
+* LIST ITEM **BOLD**
+  ITEM CONTINUATION
+1. [ ] NUMBERED TASK
+   > QUOTE
+
+> QUOTE LINE
+> CONTINUED
+
+` + "```go" + `
+fmt.Println("code")
+` + "```" + `
+
+    indented code
+
+<div>
+html block
+</div>
+
+| NAME | VALUE |
+|------|:-----:|
+| ONE  | ` + "`1`" + ` |
+
+[ref]: https://example.com
+TEXT WITH [REFERENCE][ref] AND <https://auto.link>.
+`
+
+func TestTranslate(t *testing.T) {
+	result, err := Translate(context.Background(), []byte(source), testfn.UpperText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := string(result); r != expected {
+		t.Errorf("unexpected result:\n%s", r)
+	}
+}
+
+func TestTranslate_Same(t *testing.T) {
+	texts := []string{
+		"",
+		"text",
+		"text\r\nwith windows line\r\n",
+		"hard  \nbreak\n",
+		"# title\n\n- item\n  - nested item\n",
+		"a < b && c > d, \"quotes\" 'single'",
+		"unclosed `code and [link](url",
+		"> quote\n>\n> next quote paragraph\n",
+	}
+
+	for i, text := range texts {
+		result, err := Translate(context.Background(), []byte(text), same)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if r := string(result); r != text {
+			t.Errorf("case %d: unexpected result %q", i, r)
+		}
+	}
+}
+
+func TestTranslate_SoftBreaks(t *testing.T) {
+	// a translator can add spaces around placeholders of line breaks
+	spaced := func(ctx context.Context, texts []string) ([]string, error) {
+		for i, text := range texts {
+			texts[i] = strings.ReplaceAll(text, "/>", "/> ")
+		}
+		return testfn.UpperText(ctx, texts)
+	}
+
+	var (
+		source   = "A paragraph with [a link\r\nwrapped](url) over\r\ntwo lines.\r\n\r\n> Wrapped\r\n>   quote\r\n> lines\r\n"
+		expected = "A PARAGRAPH WITH [A LINK\r\nWRAPPED](url) OVER\r\nTWO LINES.\r\n\r\n> WRAPPED\r\n>   QUOTE\r\n> LINES\r\n"
+	)
+
+	result, err := Translate(context.Background(), []byte(source), spaced)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := string(result); r != expected {
+		t.Errorf("expected %q, got %q", expected, r)
+	}
+}
+
+func TestTranslate_Lost(t *testing.T) {
+	_, err := Translate(context.Background(), []byte("text `code`"), lost)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if e := err.Error(); !strings.Contains(e, "lost protected element") {
+		t.Errorf("unexpected error: %v", e)
+	}
+}
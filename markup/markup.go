@@ -0,0 +1,101 @@
+// Package markup prepares texts with protected elements for HTML translation and restores them.
+// Protected elements are replaced by empty <x> tags, paired elements (like links or styling tags)
+// are replaced by <a> tags, so their content is translated but they are kept as is.
+package markup
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var markupRegexp = regexp.MustCompile(`<x\s+i="?(\d+)"?\s*(?:/>|>\s*</x>)|<a\s+i="?(\d+)"?\s*>|</a>`)
+
+// Text collects protected elements of one text.
+type Text struct {
+	tokens []string
+	pairs  [][2]string
+}
+
+// Escape escapes a text which should be translated.
+func Escape(s string) string {
+	return html.EscapeString(s)
+}
+
+// Token saves a protected element and returns its placeholder.
+func (t *Text) Token(s string) string {
+	t.tokens = append(t.tokens, s)
+	return fmt.Sprintf(`<x i="%d"/>`, len(t.tokens)-1)
+}
+
+// Pair saves opening and closing protected elements and returns their placeholders,
+// a translated text should be between them.
+func (t *Text) Pair(open, close string) (string, string) {
+	t.pairs = append(t.pairs, [2]string{open, close})
+	return fmt.Sprintf(`<a i="%d">`, len(t.pairs)-1), "</a>"
+}
+
+// Len returns a number of protected elements.
+func (t *Text) Len() int {
+	return len(t.tokens) + len(t.pairs)
+}
+
+// Restore converts translated HTML text back, it returns an error if some protected element was lost.
+func (t *Text) Restore(s string) (string, error) {
+	var (
+		b      strings.Builder
+		opened []int
+		start  int
+		used   = make([]bool, len(t.tokens))
+		closed = make([]bool, len(t.pairs))
+	)
+
+	for _, loc := range markupRegexp.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(html.UnescapeString(s[start:loc[0]]))
+		start = loc[1]
+
+		switch {
+		case loc[2] >= 0:
+			i, _ := strconv.Atoi(s[loc[2]:loc[3]])
+			if i >= len(t.tokens) || used[i] {
+				return "", fmt.Errorf("unexpected placeholder %q", s[loc[0]:loc[1]])
+			}
+			used[i] = true
+			b.WriteString(t.tokens[i])
+		case loc[4] >= 0:
+			i, _ := strconv.Atoi(s[loc[4]:loc[5]])
+			if i >= len(t.pairs) || closed[i] || slices.Contains(opened, i) {
+				return "", fmt.Errorf("unexpected placeholder %q", s[loc[0]:loc[1]])
+			}
+			opened = append(opened, i)
+			b.WriteString(t.pairs[i][0])
+		default:
+			n := len(opened)
+			if n == 0 {
+				return "", fmt.Errorf("unexpected closing placeholder")
+			}
+			i := opened[n-1]
+			opened = opened[:n-1]
+			closed[i] = true
+			b.WriteString(t.pairs[i][1])
+		}
+	}
+	b.WriteString(html.UnescapeString(s[start:]))
+
+	for i := range used {
+		if !used[i] {
+			return "", fmt.Errorf("lost protected element %q", t.tokens[i])
+		}
+	}
+
+	for i := range closed {
+		if !closed[i] {
+			return "", fmt.Errorf("lost protected elements %q and %q", t.pairs[i][0], t.pairs[i][1])
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,49 @@
+package markup
+
+import "testing"
+
+func TestText_Restore(t *testing.T) {
+	m := &Text{}
+	open, closing := m.Pair("<i>", "</i>")
+	text := "a &lt; b " + m.Token("{0}") + " " + open + "italic" + closing
+
+	if n := m.Len(); n != 2 {
+		t.Errorf("unexpected length %d", n)
+	}
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+		err      string
+	}{
+		{name: "same", text: text, expected: "a < b {0} <i>italic</i>"},
+		{name: "reordered", text: `<a i="0">курсив</a> <x i="0"></x> а &lt; б`, expected: "<i>курсив</i> {0} а < б"},
+		{name: "lost_token", text: `<a i="0">x</a>`, err: `lost protected element "{0}"`},
+		{name: "lost_pair", text: `<x i="0"/>`, err: `lost protected elements "<i>" and "</i>"`},
+		{name: "duplicate", text: `<x i="0"/><x i="0"/>`, err: `unexpected placeholder "<x i=\"0\"/>"`},
+		{name: "unknown", text: `<x i="1"/>`, err: `unexpected placeholder "<x i=\"1\"/>"`},
+		{name: "closing", text: `</a><x i="0"/>`, err: "unexpected closing placeholder"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := m.Restore(tc.text)
+			if err != nil {
+				if e := err.Error(); e != tc.err {
+					t.Errorf("expected error %q, got %q", tc.err, e)
+				}
+				return
+			}
+
+			if tc.err != "" {
+				t.Errorf("expected error %q", tc.err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+// Package memory implements a local translation memory.
+// Translations are stored by normalized source text and language pair,
+// so repeated texts are translated without API requests and similar ones are suggested.
+package memory
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/z0rr0/ytapigo/langtag"
+)
+
+// DefaultThreshold is a default min similarity of fuzzy matches.
+const DefaultThreshold = 0.75
+
+// idLength is a length of entry short identifier.
+const idLength = 8
+
+// Entry is a translation memory item.
+type Entry struct {
+	Source  string    `json:"source"`
+	Target  string    `json:"target"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// key returns a unique key of the entry.
+func (e *Entry) key() string {
+	return Key(e.Source, e.From, e.To)
+}
+
+// ID returns a short identifier of the entry.
+func (e *Entry) ID() string {
+	h := sha256.Sum256([]byte(e.key()))
+	return hex.EncodeToString(h[:])[:idLength]
+}
+
+// String is an implementation of String() method for Entry.
+func (e *Entry) String() string {
+	return fmt.Sprintf("%s\t%s-%s\t%s -> %s", e.ID(), e.From, e.To, e.Source, e.Target)
+}
+
+// Match is a fuzzy match of translation memory.
+type Match struct {
+	Entry *Entry
+	Score float64
+}
+
+// Suggestions are fuzzy matches of a text.
+type Suggestions []Match
+
+// Exists is an implementation of Exists() method for Suggestions.
+func (s Suggestions) Exists() bool {
+	return len(s) > 0
+}
+
+// String is an implementation of String() method for Suggestions.
+func (s Suggestions) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	items := make([]string, 0, len(s)+1)
+	items = append(items, "Memory:")
+	for _, m := range s {
+		items = append(items, fmt.Sprintf("%.0f%% %s -> %s", m.Score*100, m.Entry.Source, m.Entry.Target))
+	}
+
+	return strings.Join(items, "\n\t")
+}
+
+// Memory is a translation memory stored in a file.
+type Memory struct {
+	sync.Mutex
+	fileName string
+	entries  map[string]*Entry
+	changed  bool
+}
+
+// Normalize returns a normalized text: lower case with single spaces between words.
+func Normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// Key returns a memory key of the source text and language pair.
+func Key(source, from, to string) string {
+	return from + "-" + to + "\n" + Normalize(source)
+}
+
+// Load reads translation memory from the file, the memory is empty if the file doesn't exist.
+func Load(fileName string) (*Memory, error) {
+	m := &Memory{fileName: fileName, entries: make(map[string]*Entry)}
+
+	data, err := os.ReadFile(filepath.Clean(fileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("read translation memory: %w", err)
+	}
+
+	var entries []*Entry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse translation memory %q: %w", fileName, err)
+	}
+
+	for _, e := range entries {
+		m.entries[e.key()] = e
+	}
+
+	return m, nil
+}
+
+// Save writes translation memory to the file if it was changed.
+func (m *Memory) Save() error {
+	m.Lock()
+	defer m.Unlock()
+
+	if !m.changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(m.sorted(func(*Entry) bool { return true }), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode translation memory: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Clean(m.fileName), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write translation memory: %w", err)
+	}
+
+	m.changed = false
+	return nil
+}
+
+// sorted returns entries filtered by function ok and sorted by language pair and source text.
+func (m *Memory) sorted(ok func(*Entry) bool) []*Entry {
+	entries := make([]*Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		if ok(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b *Entry) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To), cmp.Compare(a.key(), b.key()))
+	})
+
+	return entries
+}
+
+// Get returns an exact match of the source text.
+// If there is no entry of the language pair, an entry of related language tags is used,
+// so imported "en-US" to "ru-RU" entries are found by "en" to "ru" lookups and vice versa.
+func (m *Memory) Get(source, from, to string) (*Entry, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	if e, ok := m.entries[Key(source, from, to)]; ok {
+		return e, true
+	}
+
+	var (
+		found *Entry
+		text  = Normalize(source)
+	)
+
+	for _, e := range m.entries {
+		if Normalize(e.Source) != text || !related(e.From, from) || !related(e.To, to) {
+			continue
+		}
+
+		if found == nil || e.key() < found.key() {
+			found = e // the same entry is chosen from several ones
+		}
+	}
+
+	return found, found != nil
+}
+
+// related returns true if one language tag is a truncated form of another one, like "en" and "en-US".
+func related(a, b string) bool {
+	return slices.Contains(fallbacks(a), b) || slices.Contains(fallbacks(b), a)
+}
+
+// fallbacks returns the language tag and its truncated forms.
+func fallbacks(language string) []string {
+	tag, err := langtag.Parse(language)
+	if err != nil {
+		return []string{language}
+	}
+	return tag.Fallbacks()
+}
+
+// Add adds or updates a translation.
+func (m *Memory) Add(source, target, from, to string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.add(&Entry{Source: source, Target: target, From: from, To: to})
+}
+
+// add saves the entry, creation and update times are set if they are empty.
+func (m *Memory) add(e *Entry) {
+	now := time.Now().UTC()
+	if e.Updated.IsZero() {
+		e.Updated = now
+	}
+
+	if old, ok := m.entries[e.key()]; ok {
+		if old.Target == e.Target {
+			return
+		}
+		e.Created = old.Created
+	}
+
+	if e.Created.IsZero() {
+		e.Created = e.Updated
+	}
+
+	m.entries[e.key()] = e
+	m.changed = true
+}
+
+// Import adds or updates entries keeping their creation and update times,
+// it returns a number of new or changed entries.
+func (m *Memory) Import(entries []*Entry) int {
+	m.Lock()
+	defer m.Unlock()
+
+	n := 0
+	for _, e := range entries {
+		old, ok := m.entries[e.key()]
+		m.add(e)
+
+		if !ok || old.Target != e.Target {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Fuzzy returns similar entries of the language pair with similarity not less than threshold,
+// the best matches go first, exact matches are skipped.
+func (m *Memory) Fuzzy(source, from, to string, threshold float64) Suggestions {
+	m.Lock()
+	defer m.Unlock()
+
+	var (
+		matches Suggestions
+		text    = Normalize(source)
+	)
+
+	for _, e := range m.entries {
+		if e.From != from || e.To != to {
+			continue
+		}
+
+		normalized := Normalize(e.Source)
+		if normalized == text {
+			continue
+		}
+
+		if score := Similarity(text, normalized); score >= threshold {
+			matches = append(matches, Match{Entry: e, Score: score})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Entry.Source, b.Entry.Source))
+	})
+
+	return matches
+}
+
+// Similarity returns a similarity of strings from 0 to 1 based on Levenshtein distance.
+func Similarity(a, b string) float64 {
+	n := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	if n == 0 {
+		return 1
+	}
+
+	return 1 - float64(distance([]rune(a), []rune(b)))/float64(n)
+}
+
+// distance returns Levenshtein distance of rune slices.
+func distance(a, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current := row[j]
+			row[j] = min(row[j]+1, row[j-1]+1, prev+cost)
+			prev = current
+		}
+	}
+
+	return row[len(b)]
+}
+
+// Pair returns a filter of entries by language pair, empty languages match any one.
+func Pair(from, to string) func(*Entry) bool {
+	return func(e *Entry) bool {
+		return (from == "" || e.From == from) && (to == "" || e.To == to)
+	}
+}
+
+// List returns entries which are allowed by filter.
+func (m *Memory) List(filter func(*Entry) bool) []*Entry {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.sorted(filter)
+}
+
+// Search returns entries allowed by filter which identifier is equal to query
+// or source or target text contains it (case-insensitive).
+func (m *Memory) Search(query string, filter func(*Entry) bool) []*Entry {
+	query = Normalize(query)
+
+	return m.List(func(e *Entry) bool {
+		return filter(e) && (e.ID() == query ||
+			strings.Contains(Normalize(e.Source), query) || strings.Contains(Normalize(e.Target), query))
+	})
+}
+
+// Edit changes a target text of the entry with identifier id.
+func (m *Memory) Edit(id, target string) (*Entry, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, e := range m.entries {
+		if e.ID() == id {
+			e.Target, e.Updated = target, time.Now().UTC()
+			m.changed = true
+			return e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("translation memory entry %q not found", id)
+}
+
+// Purge removes entries, it returns a number of removed ones.
+func (m *Memory) Purge(entries []*Entry) int {
+	m.Lock()
+	defer m.Unlock()
+
+	n := 0
+	for _, e := range entries {
+		if _, ok := m.entries[e.key()]; ok {
+			delete(m.entries, e.key())
+			n++
+		}
+	}
+
+	m.changed = m.changed || n > 0
+	return n
+}
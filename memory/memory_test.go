@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{expected: 1},
+		{a: "abc", b: "abc", expected: 1},
+		{a: "abc", b: "", expected: 0},
+		{a: "kitten", b: "sitting", expected: 1 - 3.0/7},
+		{a: "привет", b: "привед", expected: 1 - 1.0/6},
+	}
+
+	for i, tc := range testCases {
+		if result := Similarity(tc.a, tc.b); result != tc.expected {
+			t.Errorf("case %d: expected %v, got %v", i, tc.expected, result)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "memory.json")
+
+	m, err := Load(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Add("Hello,  world", "Привет, мир", "en", "ru")
+	m.Add("Hello, word", "Привет, слово", "en", "ru")
+	m.Add("Hello, world", "Hallo, Welt", "en", "de")
+
+	if err = m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err = Load(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := m.Get("hello, WORLD ", "en", "ru")
+	if !ok || e.Target != "Привет, мир" {
+		t.Fatalf("unexpected exact match %v", e)
+	}
+
+	if _, ok = m.Get("hello, world", "ru", "en"); ok {
+		t.Error("unexpected match of other language pair")
+	}
+
+	m.Add("Good morning", "Bom dia", "en-US", "pt-BR")
+
+	if e, ok = m.Get("good morning", "en", "pt"); !ok || e.Target != "Bom dia" {
+		t.Errorf("unexpected match of regional languages %v", e)
+	}
+
+	if _, ok = m.Get("good morning", "en", "pt-PT"); ok {
+		t.Error("unexpected match of other region")
+	}
+
+	m.Purge(m.List(Pair("en-US", "")))
+
+	suggestions := m.Fuzzy("hello, worlds", "en", "ru", DefaultThreshold)
+	if n := len(suggestions); n != 2 || suggestions[0].Entry.Target != "Привет, мир" {
+		t.Fatalf("unexpected suggestions %v", suggestions)
+	}
+
+	if s := suggestions.String(); !strings.HasPrefix(s, "Memory:\n\t92% Hello,  world -> Привет, мир") {
+		t.Errorf("unexpected suggestions string %q", s)
+	}
+
+	if n := len(m.Fuzzy("Hello, world", "en", "ru", DefaultThreshold)); n != 1 {
+		t.Errorf("exact match must be skipped, got %d suggestions", n)
+	}
+
+	if n := len(m.List(Pair("en", ""))); n != 3 {
+		t.Errorf("unexpected entries count %d", n)
+	}
+
+	found := m.Search("welt", Pair("", ""))
+	if len(found) != 1 || found[0].To != "de" {
+		t.Fatalf("unexpected search result %v", found)
+	}
+
+	if e, err = m.Edit(found[0].ID(), "Hallo, Welt!"); err != nil || e.Target != "Hallo, Welt!" {
+		t.Errorf("unexpected edit result %v: %v", e, err)
+	}
+
+	if _, err = m.Edit("unknown", "x"); err == nil {
+		t.Error("expected error")
+	}
+
+	if n := m.Purge(m.List(Pair("en", "ru"))); n != 2 {
+		t.Errorf("unexpected purged count %d", n)
+	}
+
+	if err = m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err = Load(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries := m.List(Pair("", "")); len(entries) != 1 || entries[0].Target != "Hallo, Welt!" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
@@ -0,0 +1,115 @@
+package offline
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// dictdAlphabet is used for offsets and sizes numbers in dictd ".index" file.
+const dictdAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// dictdInfoPrefix is a prefix of dictd service entries like "00-database-short".
+const dictdInfoPrefix = "00-database-"
+
+// dictdNumber decodes base64 encoded number.
+func dictdNumber(s string) (int64, error) {
+	var n int64
+	if s == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	for _, c := range s {
+		i := strings.IndexRune(dictdAlphabet, c)
+		if i < 0 {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		n = n*64 + int64(i)
+	}
+
+	return n, nil
+}
+
+// openDictd opens dictd database by its ".index" file.
+func openDictd(fileName string) (*Dictionary, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open dictionary index: %w", err)
+	}
+	defer func() {
+		_ = f.Close() // read only file
+	}()
+
+	var (
+		d       = &Dictionary{index: make(map[string][]entry), parse: dictdArticle}
+		scanner = bufio.NewScanner(f)
+		line    int
+		info    *entry
+	)
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+
+		e := entry{word: fields[0]}
+		if e.offset, err = dictdNumber(fields[1]); err != nil {
+			return nil, fmt.Errorf("dictionary index line %d: %w", line, err)
+		}
+
+		if e.size, err = dictdNumber(fields[2]); err != nil {
+			return nil, fmt.Errorf("dictionary index line %d: %w", line, err)
+		}
+
+		if strings.HasPrefix(e.word, dictdInfoPrefix) {
+			if e.word == dictdInfoPrefix+"short" {
+				info = &e
+			}
+			continue
+		}
+
+		d.add(e)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dictionary index: %w", err)
+	}
+
+	if d.data, err = openData(strings.TrimSuffix(fileName, ".index")); err != nil {
+		return nil, err
+	}
+
+	if info != nil {
+		if a, e := d.readArticle(*info); e == nil && len(a.lines) > 0 {
+			d.Name = a.lines[0]
+		}
+	}
+
+	return d, nil
+}
+
+// dictdArticle parses article text. Its first line usually contains the headword
+// and an optional transcription like "word [wɜːd]" or "word /wɜːd/", so it is skipped.
+func dictdArticle(word string, data []byte) (*article, error) {
+	a := &article{lines: textLines(data, false)}
+	if len(a.lines) == 0 {
+		return a, nil
+	}
+
+	first := a.lines[0]
+	if len(first) < len(word) || !strings.EqualFold(first[:len(word)], word) {
+		return a, nil
+	}
+
+	a.lines = a.lines[1:]
+	ts := strings.TrimSpace(first[len(word):])
+
+	if n := len(ts); n > 1 && ((ts[0] == '[' && ts[n-1] == ']') || (ts[0] == '/' && ts[n-1] == '/')) {
+		a.ts = ts[1 : n-1]
+	}
+
+	return a, nil
+}
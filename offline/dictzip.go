@@ -0,0 +1,267 @@
+package offline
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// gzip header flags, see RFC 1952.
+const (
+	gzipFlagHCRC    = 1 << 1
+	gzipFlagExtra   = 1 << 2
+	gzipFlagName    = 1 << 3
+	gzipFlagComment = 1 << 4
+)
+
+// dataReader reads dictionary articles by offsets of uncompressed data.
+type dataReader interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// memoryData is an uncompressed data without random access in compressed file.
+type memoryData struct {
+	*bytes.Reader
+}
+
+// Close is an implementation of io.Closer interface.
+func (memoryData) Close() error {
+	return nil
+}
+
+// dictzip is a gzip file with random access to independently compressed chunks,
+// see dictzip(1) manual page for "RA" extra field description.
+type dictzip struct {
+	file      *os.File
+	chunkSize int64
+	offsets   []int64 // compressed chunks offsets, the last one is the end of compressed data
+	size      int64   // uncompressed data size
+	mu        sync.Mutex
+	cached    int // index of cached chunk, it is guarded by mu like the chunk
+	chunk     []byte
+}
+
+// openData opens uncompressed ".dict" file or compressed ".dict.dz" one.
+func openData(base string) (dataReader, error) {
+	f, err := os.Open(base + ".dict")
+	if err == nil {
+		return f, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("open dictionary data: %w", err)
+	}
+
+	if f, err = os.Open(base + ".dict.dz"); err != nil {
+		return nil, fmt.Errorf("open dictionary data: %w", err)
+	}
+
+	r, err := newDictzip(f)
+	if err != nil {
+		if e := f.Close(); e != nil {
+			err = errors.Join(err, e)
+		}
+		return nil, fmt.Errorf("read dictionary data %q: %w", f.Name(), err)
+	}
+
+	return r, nil
+}
+
+// newDictzip returns a random access reader of dictzip file
+// or uncompressed data in memory if it is a simple gzip file.
+func newDictzip(f *os.File) (dataReader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := readChunks(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	if d != nil {
+		return d, nil
+	}
+
+	// no random access data
+	zr, err := gzip.NewReader(io.NewSectionReader(f, 0, info.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	return memoryData{bytes.NewReader(data)}, f.Close()
+}
+
+// readChunks parses gzip header and returns dictzip reader, the result is nil if there is no chunks table.
+func readChunks(f *os.File, fileSize int64) (*dictzip, error) {
+	const headerSize, trailerSize = 10, 8
+	var header [headerSize]byte
+
+	if _, err := f.ReadAt(header[:], 0); err != nil {
+		return nil, err
+	}
+
+	if header[0] != 0x1f || header[1] != 0x8b || header[2] != 8 {
+		return nil, gzip.ErrHeader
+	}
+
+	flags, offset := header[3], int64(headerSize)
+	if flags&gzipFlagExtra == 0 {
+		return nil, nil
+	}
+
+	var size [2]byte
+	if _, err := f.ReadAt(size[:], offset); err != nil {
+		return nil, err
+	}
+
+	extra := make([]byte, binary.LittleEndian.Uint16(size[:]))
+	if _, err := f.ReadAt(extra, offset+2); err != nil {
+		return nil, err
+	}
+	offset += 2 + int64(len(extra))
+
+	for _, flag := range []byte{gzipFlagName, gzipFlagComment} {
+		if flags&flag != 0 {
+			n, err := zeroTerminated(f, offset)
+			if err != nil {
+				return nil, err
+			}
+			offset += n
+		}
+	}
+
+	if flags&gzipFlagHCRC != 0 {
+		offset += 2
+	}
+
+	var trailer [trailerSize]byte
+	if _, err := f.ReadAt(trailer[:], fileSize-trailerSize); err != nil {
+		return nil, err
+	}
+
+	d := &dictzip{file: f, cached: -1, size: int64(binary.LittleEndian.Uint32(trailer[4:]))}
+	for len(extra) >= 4 {
+		n := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if len(extra) < 4+n {
+			return nil, gzip.ErrHeader
+		}
+
+		if extra[0] == 'R' && extra[1] == 'A' {
+			return d, d.setChunks(extra[4:4+n], offset)
+		}
+		extra = extra[4+n:]
+	}
+
+	return nil, nil
+}
+
+// setChunks sets chunks offsets from "RA" field: version, chunk length, chunks count and their sizes.
+func (d *dictzip) setChunks(field []byte, offset int64) error {
+	if len(field) < 6 {
+		return gzip.ErrHeader
+	}
+
+	d.chunkSize = int64(binary.LittleEndian.Uint16(field[2:4]))
+	count := int(binary.LittleEndian.Uint16(field[4:6]))
+	if d.chunkSize == 0 || len(field) < 6+2*count {
+		return gzip.ErrHeader
+	}
+
+	d.offsets = make([]int64, count+1)
+	d.offsets[0] = offset
+	for i := range count {
+		d.offsets[i+1] = d.offsets[i] + int64(binary.LittleEndian.Uint16(field[6+2*i:]))
+	}
+
+	return nil
+}
+
+// zeroTerminated returns a length of zero terminated string including the zero byte.
+func zeroTerminated(f *os.File, offset int64) (int64, error) {
+	var (
+		b [1]byte
+		n int64
+	)
+
+	for {
+		if _, err := f.ReadAt(b[:], offset+n); err != nil {
+			return 0, err
+		}
+
+		n++
+		if b[0] == 0 {
+			return n, nil
+		}
+	}
+}
+
+// readChunk returns uncompressed chunk, the last one is cached.
+// The returned chunk is not modified, so it is safe for concurrent ReadAt calls.
+func (d *dictzip) readChunk(i int) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i == d.cached {
+		return d.chunk, nil
+	}
+
+	size := min(d.chunkSize, d.size-int64(i)*d.chunkSize)
+	r := flate.NewReader(io.NewSectionReader(d.file, d.offsets[i], d.offsets[i+1]-d.offsets[i]))
+
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(r, chunk); err != nil {
+		return nil, fmt.Errorf("decompress chunk %d: %w", i, err)
+	}
+
+	d.cached, d.chunk = i, chunk
+	return chunk, nil
+}
+
+// ReadAt is an implementation of io.ReaderAt interface.
+func (d *dictzip) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= d.size {
+		return 0, io.EOF
+	}
+
+	var n int
+	for n < len(p) && offset < d.size {
+		i := int(offset / d.chunkSize)
+		if i >= len(d.offsets)-1 {
+			return n, io.ErrUnexpectedEOF
+		}
+
+		chunk, err := d.readChunk(i)
+		if err != nil {
+			return n, err
+		}
+
+		m := copy(p[n:], chunk[offset-int64(i)*d.chunkSize:])
+		n += m
+		offset += int64(m)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// Close is an implementation of io.Closer interface.
+func (d *dictzip) Close() error {
+	return d.file.Close()
+}
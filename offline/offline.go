@@ -0,0 +1,123 @@
+// Package offline implements dictionary lookups in local StarDict and dictd databases.
+//
+// StarDict dictionary is set by its ".ifo" file, ".idx" (or ".idx.gz") and ".dict" (or ".dict.dz")
+// files should be placed nearby. Dictd database is set by its ".index" file with ".dict" or ".dict.dz" data.
+package offline
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+)
+
+var (
+	lineBreakRegexp = regexp.MustCompile(`(?i)<br\s*/?>|</?(?:p|div|li|ul|ol|dd|dt|blockquote)\b[^>]*>`)
+	tagRegexp       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// entry is a position of the word article in dictionary data file.
+type entry struct {
+	word   string
+	offset int64
+	size   int64
+}
+
+// article is a parsed dictionary article: transcription and definition lines.
+type article struct {
+	ts    string
+	lines []string
+}
+
+// Dictionary is an opened local dictionary.
+type Dictionary struct {
+	Name  string
+	index map[string][]entry // entries by lower case words
+	data  dataReader
+	parse func(word string, data []byte) (*article, error)
+}
+
+// Open opens StarDict or dictd dictionary by its ".ifo" or ".index" file.
+func Open(fileName string) (*Dictionary, error) {
+	switch ext := filepath.Ext(fileName); ext {
+	case ".ifo":
+		return openStarDict(fileName)
+	case ".index":
+		return openDictd(fileName)
+	default:
+		return nil, fmt.Errorf("unknown offline dictionary format %q, expected .ifo or .index file", ext)
+	}
+}
+
+// Close closes dictionary data file.
+func (d *Dictionary) Close() error {
+	return d.data.Close()
+}
+
+// add adds the word to dictionary index.
+func (d *Dictionary) add(e entry) {
+	key := strings.ToLower(e.word)
+	d.index[key] = append(d.index[key], e)
+}
+
+// Lookup returns dictionary articles of the word, every definition line is a translation item.
+// The result has no articles if the word is not found.
+func (d *Dictionary) Lookup(word string) (*dictionary.Response, error) {
+	response := &dictionary.Response{}
+
+	for _, e := range d.index[strings.ToLower(strings.TrimSpace(word))] {
+		a, err := d.readArticle(e)
+		if err != nil {
+			return nil, err
+		}
+
+		def := dictionary.Article{Text: e.word, Ts: a.ts}
+		for _, line := range a.lines {
+			def.Tr = append(def.Tr, dictionary.TrItem{TextPosGen: dictionary.TextPosGen{Text: line}})
+		}
+
+		if len(def.Tr) > 0 || def.Ts != "" {
+			response.Def = append(response.Def, def)
+		}
+	}
+
+	return response, nil
+}
+
+// readArticle reads and parses the entry article.
+func (d *Dictionary) readArticle(e entry) (*article, error) {
+	data := make([]byte, e.size)
+	if _, err := io.ReadFull(io.NewSectionReader(d.data, e.offset, e.size), data); err != nil {
+		return nil, fmt.Errorf("read article %q: %w", e.word, err)
+	}
+
+	a, err := d.parse(e.word, data)
+	if err != nil {
+		return nil, fmt.Errorf("parse article %q: %w", e.word, err)
+	}
+
+	return a, nil
+}
+
+// textLines returns not empty trimmed lines of the text, markup tags are removed if it's required.
+func textLines(data []byte, isMarkup bool) []string {
+	text := string(bytes.ToValidUTF8(data, nil))
+	if isMarkup {
+		text = lineBreakRegexp.ReplaceAllString(text, "\n")
+		text = html.UnescapeString(tagRegexp.ReplaceAllString(text, ""))
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,308 @@
+package offline
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testArticle is a word article of test dictionaries.
+type testArticle struct {
+	word string
+	data []byte
+}
+
+// writeFile creates a file in the directory.
+func writeFile(t *testing.T, name string, data []byte) {
+	if err := os.WriteFile(name, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// gzipData returns gzip compressed data.
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// dictzipData returns dictzip compressed data with independent chunks.
+func dictzipData(t *testing.T, data []byte, chunkSize int) []byte {
+	var (
+		chunks [][]byte
+		sizes  []byte
+	)
+
+	for i := 0; i < len(data); i += chunkSize {
+		var buf bytes.Buffer
+		fw, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = fw.Write(data[i:min(i+chunkSize, len(data))]); err != nil {
+			t.Fatal(err)
+		}
+
+		if i+chunkSize < len(data) {
+			err = fw.Flush()
+		} else {
+			err = fw.Close()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chunks = append(chunks, buf.Bytes())
+		sizes = binary.LittleEndian.AppendUint16(sizes, uint16(buf.Len()))
+	}
+
+	field := binary.LittleEndian.AppendUint16([]byte{1, 0}, uint16(chunkSize))
+	field = append(binary.LittleEndian.AppendUint16(field, uint16(len(chunks))), sizes...)
+	extra := append(binary.LittleEndian.AppendUint16([]byte{'R', 'A'}, uint16(len(field))), field...)
+
+	// header with extra field and file name
+	result := []byte{0x1f, 0x8b, 8, gzipFlagExtra | gzipFlagName, 0, 0, 0, 0, 2, 3}
+	result = append(binary.LittleEndian.AppendUint16(result, uint16(len(extra))), extra...)
+	result = append(result, "test.dict\x00"...)
+	result = append(result, bytes.Join(chunks, nil)...)
+
+	// CRC is not checked by random access reader
+	result = binary.LittleEndian.AppendUint32(result, 0)
+	return binary.LittleEndian.AppendUint32(result, uint32(len(data)))
+}
+
+// starDictFiles returns index and data of StarDict dictionary.
+func starDictFiles(articles []testArticle) ([]byte, []byte) {
+	var index, data []byte
+
+	for _, a := range articles {
+		index = append(append(index, a.word...), 0)
+		index = binary.BigEndian.AppendUint32(index, uint32(len(data)))
+		index = binary.BigEndian.AppendUint32(index, uint32(len(a.data)))
+		data = append(data, a.data...)
+	}
+
+	return index, data
+}
+
+// dictdFiles returns index and data of dictd database.
+func dictdFiles(articles []testArticle) ([]byte, []byte) {
+	var index, data []byte
+
+	number := func(n int) string {
+		if n == 0 {
+			return "A"
+		}
+
+		var s []byte
+		for ; n > 0; n /= 64 {
+			s = append([]byte{dictdAlphabet[n%64]}, s...)
+		}
+		return string(s)
+	}
+
+	for _, a := range articles {
+		index = append(index, a.word+"\t"+number(len(data))+"\t"+number(len(a.data))+"\n"...)
+		data = append(data, a.data...)
+	}
+
+	return index, data
+}
+
+func TestOpen(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		longText  = strings.Repeat("длинный текст ", 20)
+		sequences = []testArticle{
+			{word: "Apple", data: []byte("ˈæpl\x00яблоко\nяблоня")},
+			{word: "go", data: []byte("\x00идти")},
+			{word: "long", data: []byte("lɒŋ\x00" + longText)},
+		}
+		marked = []testArticle{
+			{word: "apple", data: []byte("m1. яблоко\x00h<b>2.</b> яблоня<br>fruit &amp; tree\x00")},
+		}
+		databases = []testArticle{
+			{word: dictdInfoPrefix + "short", data: []byte("00-database-short\n   Test English-Russian\n")},
+			{word: "apple", data: []byte("apple [ˈæpl]\n   яблоко\n   яблоня\n")},
+			{word: "go", data: []byte("go\n   идти\n")},
+		}
+	)
+
+	index, data := starDictFiles(sequences)
+	writeFile(t, filepath.Join(dir, "seq.ifo"), []byte("StarDict's dict ifo file\nversion=2.4.2\nbookname=Test\nsametypesequence=tm\n"))
+	writeFile(t, filepath.Join(dir, "seq.idx"), index)
+	writeFile(t, filepath.Join(dir, "seq.dict"), data)
+
+	// compressed copy with small chunks
+	writeFile(t, filepath.Join(dir, "dz.ifo"), []byte("StarDict's dict ifo file\nbookname=Compressed\nsametypesequence=tm\n"))
+	writeFile(t, filepath.Join(dir, "dz.idx.gz"), gzipData(t, index))
+	writeFile(t, filepath.Join(dir, "dz.dict.dz"), dictzipData(t, data, 16))
+
+	index, data = starDictFiles(marked)
+	writeFile(t, filepath.Join(dir, "marked.ifo"), []byte("StarDict's dict ifo file\nbookname=Marked\n"))
+	writeFile(t, filepath.Join(dir, "marked.idx"), index)
+	writeFile(t, filepath.Join(dir, "marked.dict"), data)
+
+	index, data = dictdFiles(databases)
+	writeFile(t, filepath.Join(dir, "dictd.index"), index)
+	writeFile(t, filepath.Join(dir, "dictd.dict.dz"), gzipData(t, data))
+
+	writeFile(t, filepath.Join(dir, "bad.ifo"), []byte("not a dictionary\n"))
+	writeFile(t, filepath.Join(dir, "no_data.index"), index)
+
+	testCases := []struct {
+		name     string
+		file     string
+		word     string
+		dictName string
+		expected string
+		err      string
+	}{
+		{name: "stardict", file: "seq.ifo", word: "apple", dictName: "Test", expected: "Apple [ˈæpl]\n\tяблоко\n\tяблоня"},
+		{name: "stardict_no_ts", file: "seq.ifo", word: " GO ", dictName: "Test", expected: "go\n\tидти"},
+		{name: "stardict_not_found", file: "seq.ifo", word: "went", dictName: "Test"},
+		{name: "dictzip", file: "dz.ifo", word: "long", dictName: "Compressed", expected: "long [lɒŋ]\n\t" + strings.TrimSpace(longText)},
+		{name: "dictzip_first", file: "dz.ifo", word: "apple", dictName: "Compressed", expected: "Apple [ˈæpl]\n\tяблоко\n\tяблоня"},
+		{name: "types", file: "marked.ifo", word: "apple", dictName: "Marked", expected: "apple\n\t1. яблоко\n\t2. яблоня\n\tfruit & tree"},
+		{name: "dictd", file: "dictd.index", word: "Apple", dictName: "Test English-Russian", expected: "apple [ˈæpl]\n\tяблоко\n\tяблоня"},
+		{name: "dictd_service", file: "dictd.index", word: dictdInfoPrefix + "short", dictName: "Test English-Russian"},
+		{name: "bad_info", file: "bad.ifo", err: "not StarDict info file"},
+		{name: "no_data", file: "no_data.index", err: "open dictionary data: "},
+		{name: "no_index", file: "not_exists.ifo", err: "open dictionary info: "},
+		{name: "unknown", file: "dict.txt", err: `unknown offline dictionary format ".txt", expected .ifo or .index file`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := Open(filepath.Join(dir, tc.file))
+			if err != nil {
+				if tc.err == "" || !strings.HasPrefix(err.Error(), tc.err) {
+					t.Errorf("expected error %q, got %q", tc.err, err)
+				}
+				return
+			}
+			defer func() {
+				if e := d.Close(); e != nil {
+					t.Error(e)
+				}
+			}()
+
+			if tc.err != "" {
+				t.Fatalf("expected error %q", tc.err)
+			}
+
+			if d.Name != tc.dictName {
+				t.Errorf("expected name %q, got %q", tc.dictName, d.Name)
+			}
+
+			response, err := d.Lookup(tc.word)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if s := response.String(); s != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, s)
+			}
+
+			if response.Exists() != (tc.expected != "") {
+				t.Errorf("unexpected exists %v", response.Exists())
+			}
+		})
+	}
+}
+
+func TestDictionary_LookupConcurrent(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		articles = []testArticle{
+			{word: "apple", data: []byte("ˈæpl\x00" + strings.Repeat("яблоко ", 10))},
+			{word: "go", data: []byte("\x00" + strings.Repeat("идти ", 10))},
+			{word: "long", data: []byte("lɒŋ\x00" + strings.Repeat("длинный ", 10))},
+		}
+	)
+
+	index, data := starDictFiles(articles)
+	writeFile(t, filepath.Join(dir, "dz.ifo"), []byte("StarDict's dict ifo file\nbookname=Compressed\nsametypesequence=tm\n"))
+	writeFile(t, filepath.Join(dir, "dz.idx"), index)
+	writeFile(t, filepath.Join(dir, "dz.dict.dz"), dictzipData(t, data, 16))
+
+	d, err := Open(filepath.Join(dir, "dz.ifo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if e := d.Close(); e != nil {
+			t.Error(e)
+		}
+	}()
+
+	expected := make(map[string]string, len(articles))
+	for _, article := range articles {
+		response, e := d.Lookup(article.word)
+		if e != nil {
+			t.Fatal(e)
+		}
+		expected[article.word] = response.String()
+	}
+
+	var wg sync.WaitGroup
+	for i := range 30 {
+		word := articles[i%len(articles)].word
+
+		wg.Go(func() {
+			response, e := d.Lookup(word)
+			if e != nil {
+				t.Error(e)
+				return
+			}
+
+			if s := response.String(); s != expected[word] {
+				t.Errorf("expected %q, got %q", expected[word], s)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestDictdNumber(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected int64
+		err      bool
+	}{
+		{value: "A", expected: 0},
+		{value: "B", expected: 1},
+		{value: "BA", expected: 64},
+		{value: "Bk8", expected: 6460},
+		{value: "", err: true},
+		{value: "A=", err: true},
+	}
+
+	for _, tc := range testCases {
+		n, err := dictdNumber(tc.value)
+		if (err != nil) != tc.err {
+			t.Errorf("unexpected error for %q: %v", tc.value, err)
+			continue
+		}
+
+		if n != tc.expected {
+			t.Errorf("expected %d for %q, got %d", tc.expected, tc.value, n)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// starDictMagic is the first line of StarDict ".ifo" file.
+const starDictMagic = "StarDict's dict ifo file"
+
+// starDictInfo is a content of StarDict ".ifo" file.
+type starDictInfo struct {
+	name         string
+	offsetBits   int
+	typeSequence string
+}
+
+// readStarDictInfo reads StarDict ".ifo" file.
+func readStarDictInfo(fileName string) (*starDictInfo, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open dictionary info: %w", err)
+	}
+	defer func() {
+		_ = f.Close() // read only file
+	}()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "\ufeff") != starDictMagic {
+		return nil, fmt.Errorf("not StarDict info file %q", fileName)
+	}
+
+	info := &starDictInfo{offsetBits: 32}
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch value = strings.TrimSpace(value); strings.TrimSpace(key) {
+		case "bookname":
+			info.name = value
+		case "idxoffsetbits":
+			if value == "64" {
+				info.offsetBits = 64
+			}
+		case "sametypesequence":
+			info.typeSequence = value
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dictionary info: %w", err)
+	}
+
+	return info, nil
+}
+
+// readStarDictIndex reads uncompressed ".idx" file or compressed ".idx.gz" one.
+func readStarDictIndex(base string) ([]byte, error) {
+	data, err := os.ReadFile(base + ".idx")
+	if err == nil {
+		return data, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read dictionary index: %w", err)
+	}
+
+	f, err := os.Open(base + ".idx.gz")
+	if err != nil {
+		return nil, fmt.Errorf("read dictionary index: %w", err)
+	}
+	defer func() {
+		_ = f.Close() // read only file
+	}()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read dictionary index: %w", err)
+	}
+
+	if data, err = io.ReadAll(zr); err != nil {
+		return nil, fmt.Errorf("read dictionary index: %w", err)
+	}
+
+	return data, nil
+}
+
+// openStarDict opens StarDict dictionary by its ".ifo" file.
+func openStarDict(fileName string) (*Dictionary, error) {
+	info, err := readStarDictInfo(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(fileName, ".ifo")
+	index, err := readStarDictIndex(base)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dictionary{
+		Name:  info.name,
+		index: make(map[string][]entry),
+		parse: func(_ string, data []byte) (*article, error) {
+			return starDictArticle(data, info.typeSequence)
+		},
+	}
+
+	offsetSize := info.offsetBits / 8
+	for len(index) > 0 {
+		i := bytes.IndexByte(index, 0)
+		if i < 0 || len(index) < i+1+offsetSize+4 {
+			return nil, fmt.Errorf("broken dictionary index %q", base)
+		}
+
+		e := entry{word: string(index[:i])}
+		index = index[i+1:]
+
+		if offsetSize == 8 {
+			e.offset = int64(binary.BigEndian.Uint64(index))
+		} else {
+			e.offset = int64(binary.BigEndian.Uint32(index))
+		}
+
+		e.size = int64(binary.BigEndian.Uint32(index[offsetSize:]))
+		index = index[offsetSize+4:]
+		d.add(e)
+	}
+
+	if d.data, err = openData(base); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// starDictArticle parses article fields. Every field has a type, lower case types are text ones,
+// upper case types are binary data with a size. If the types sequence is set, fields don't have type marks
+// and the last field has no size or zero terminator.
+func starDictArticle(data []byte, typeSequence string) (*article, error) {
+	var (
+		a     = &article{}
+		types = []byte(typeSequence)
+		last  bool
+		field []byte
+	)
+
+	for i := 0; len(data) > 0 && (len(types) == 0 || i < len(types)); i++ {
+		var fieldType byte
+		if len(types) > 0 {
+			fieldType, last = types[i], i == len(types)-1
+		} else {
+			fieldType, data = data[0], data[1:]
+		}
+
+		switch {
+		case last:
+			field, data = data, nil
+		case unicode.IsLower(rune(fieldType)):
+			n := bytes.IndexByte(data, 0)
+			if n < 0 {
+				n = len(data) // last field can be without zero byte
+			}
+			field, data = data[:n], data[min(n+1, len(data)):]
+		default:
+			if len(data) < 4 {
+				return nil, fmt.Errorf("broken field %q", fieldType)
+			}
+
+			n := int(binary.BigEndian.Uint32(data))
+			if len(data) < 4+n {
+				return nil, fmt.Errorf("broken field %q", fieldType)
+			}
+			field, data = data[4:4+n], data[4+n:]
+		}
+
+		switch fieldType {
+		case 't':
+			a.ts = strings.TrimSpace(string(field))
+		case 'm', 'l', 'y', 'k', 'w', 'n':
+			a.lines = append(a.lines, textLines(field, false)...)
+		case 'g', 'h', 'x':
+			a.lines = append(a.lines, textLines(field, true)...)
+		}
+		// resources and binary data are skipped
+	}
+
+	return a, nil
+}
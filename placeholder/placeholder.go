@@ -0,0 +1,167 @@
+// Package placeholder protects placeholders of plain texts during translation.
+// Format verbs (like %d, %1$s, %(name)s), ICU and brace placeholders (like {name}, {{count}}, ${var}),
+// HTML tags and entities and user defined tokens are masked before translation and restored afterwards.
+package placeholder
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/markup"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// builtin are patterns of common placeholders, brace ones are found separately because they can be nested.
+var builtin = []*regexp.Regexp{
+	// printf-like format verbs, including positional and named (Python) ones
+	regexp.MustCompile(`%(?:\d+\$|\([A-Za-z_]\w*\))?[-+#0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:hh|ll|[hlLqjzt])?[a-zA-Z@%]`),
+	// HTML tags and entities
+	regexp.MustCompile(`</?[A-Za-z][^<>]*>|&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9A-Fa-f]+);`),
+}
+
+// Protector masks placeholders of texts.
+type Protector struct {
+	patterns []*regexp.Regexp
+}
+
+// New creates a new protector, patterns are user defined regular expressions of protected tokens.
+func New(patterns []string) (*Protector, error) {
+	p := &Protector{patterns: slices.Clone(builtin)}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid placeholder pattern %q: %w", pattern, err)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+
+	return p, nil
+}
+
+// braces returns locations of balanced brace groups, a group can have "$", "%" or "#" prefix.
+func braces(text string) [][]int {
+	var (
+		locations [][]int
+		start     = -1
+		depth     int
+	)
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			if depth == 0 {
+				start = i
+				if i > 0 && strings.IndexByte("$%#", text[i-1]) >= 0 {
+					start--
+				}
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue // not opened brace
+			}
+
+			depth--
+			if depth == 0 {
+				locations = append(locations, []int{start, i + 1})
+			}
+		}
+	}
+
+	return locations
+}
+
+// locations returns sorted not overlapped locations of placeholders.
+func (p *Protector) locations(text string) [][]int {
+	locations := braces(text)
+	for _, re := range p.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[0] < loc[1] {
+				locations = append(locations, loc)
+			}
+		}
+	}
+
+	// the earliest and the longest placeholders win
+	slices.SortFunc(locations, func(a, b []int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return b[1] - a[1]
+	})
+
+	result := locations[:0]
+	for _, loc := range locations {
+		if n := len(result); n > 0 && loc[0] < result[n-1][1] {
+			continue
+		}
+		result = append(result, loc)
+	}
+
+	return result
+}
+
+// Mask converts a plain text to HTML text with protected placeholders.
+func (p *Protector) Mask(text string) (string, *markup.Text) {
+	var (
+		b     strings.Builder
+		m     = &markup.Text{}
+		start int
+	)
+
+	for _, loc := range p.locations(text) {
+		b.WriteString(markup.Escape(text[start:loc[0]]))
+		b.WriteString(m.Token(text[loc[0]:loc[1]]))
+		start = loc[1]
+	}
+
+	b.WriteString(markup.Escape(text[start:]))
+	return b.String(), m
+}
+
+// Restore returns a plain text with restored placeholders of the translated HTML text.
+// It returns an error if some placeholder is missing.
+func Restore(translated string, m *markup.Text) (string, error) {
+	text, err := m.Restore(translated)
+	if err != nil {
+		return "", fmt.Errorf("placeholder is missing in translation %q: %w", translated, err)
+	}
+	return text, nil
+}
+
+// Wrap returns a plain text translation function which masks placeholders
+// and calls fn, the last one should translate HTML texts.
+func (p *Protector) Wrap(fn translation.BatchFunc) translation.BatchFunc {
+	return func(ctx context.Context, texts []string) ([]string, error) {
+		var (
+			masked = make([]string, len(texts))
+			tags   = make([]*markup.Text, len(texts))
+		)
+
+		for i, text := range texts {
+			masked[i], tags[i] = p.Mask(text)
+		}
+
+		translations, err := fn(ctx, masked)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(translations) != len(texts) {
+			return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(texts))
+		}
+
+		result := make([]string, len(translations))
+		for i, item := range translations {
+			if result[i], err = Restore(item, tags[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
+}
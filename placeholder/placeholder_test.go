@@ -0,0 +1,99 @@
+package placeholder
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProtector_Mask(t *testing.T) {
+	p, err := New([]string{`:[a-z]+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "empty"},
+		{name: "plain", text: "a < b", expected: "a &lt; b"},
+		{
+			name:     "verbs",
+			text:     "Hello, %s! You have %d messages, 100%% done %1$s %(name)s %.2f",
+			expected: `Hello, <x i="0"/>! You have <x i="1"/> messages, 100<x i="2"/> done <x i="3"/> <x i="4"/> <x i="5"/>`,
+		},
+		{
+			name:     "braces",
+			text:     "Hello, {name}! {{count}} ${var} %{ruby} {n, plural, one {# item} other {# items}} }{",
+			expected: `Hello, <x i="0"/>! <x i="1"/> <x i="2"/> <x i="3"/> <x i="4"/> }{`,
+		},
+		{
+			name:     "html",
+			text:     "<b>bold</b> &amp; &#169; & co",
+			expected: `<x i="0"/>bold<x i="1"/> <x i="2"/> <x i="3"/> &amp; co`,
+		},
+		{name: "user", text: "go to :page now", expected: `go to <x i="0"/> now`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			result, tags := p.Mask(tc.text)
+			if result != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, result)
+			}
+
+			text, err := Restore(result, tags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if text != tc.text {
+				t.Errorf("expected restored %q, got %q", tc.text, text)
+			}
+		})
+	}
+
+	if _, err = New([]string{"["}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestProtector_Wrap(t *testing.T) {
+	p, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fake HTML translation: it reorders placeholders, translates text and loses some placeholders
+	fn := p.Wrap(func(_ context.Context, texts []string) ([]string, error) {
+		result := make([]string, len(texts))
+		for i, text := range texts {
+			switch text {
+			case `Hello, <x i="0"/>! You have <x i="1"/> messages`:
+				result[i] = `У вас <x i="1"/> сообщений, <x i="0"/>!`
+			case `lost <x i="0"/>`:
+				result[i] = "потерян"
+			default:
+				result[i] = strings.ToUpper(text)
+			}
+		}
+		return result, nil
+	})
+
+	result, err := fn(context.Background(), []string{"Hello, {name}! You have %d messages", "a & b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "У вас %d сообщений, {name}!|A & B"; strings.Join(result, "|") != expected {
+		t.Errorf("expected %q, got %q", expected, strings.Join(result, "|"))
+	}
+
+	_, err = fn(context.Background(), []string{"lost {name}"})
+	if expected := `placeholder is missing in translation "потерян": lost protected element "{name}"`; err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
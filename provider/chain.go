@@ -0,0 +1,397 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/health"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// Capabilities of providers, they are parts of health tracker keys like "dictionary:yandex".
+const (
+	capabilityTranslation = "translation"
+	capabilityDictionary  = "dictionary"
+	capabilitySpelling    = "spelling"
+)
+
+// Trace is a set of hooks of providers chains, it is set to a request context like httptrace.ClientTrace.
+type Trace struct {
+	// Answered is called with a capability and a name of the provider which answered.
+	Answered func(capability, name string)
+}
+
+// traceKey is a context key of Trace.
+type traceKey struct{}
+
+// WithTrace returns a context with providers chains hooks.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// answered calls Answered hook of the context trace if it is set.
+func answered(ctx context.Context, capability, name string) {
+	if trace, ok := ctx.Value(traceKey{}).(*Trace); ok && trace.Answered != nil {
+		trace.Answered(capability, name)
+	}
+}
+
+// named is a provider with a name.
+type named interface {
+	Name() string
+}
+
+// chain is an ordered list of providers of one capability,
+// the next provider is used if the previous one is unavailable.
+type chain[T named] struct {
+	capability string
+	items      []T
+	tracker    *health.Tracker
+	logger     *log.Logger
+}
+
+// Name returns names of all chain providers.
+func (c *chain[T]) Name() string {
+	return strings.Join(c.members(), ",")
+}
+
+// members returns names of all chain providers.
+func (c *chain[T]) members() []string {
+	names := make([]string, len(c.items))
+	for i, item := range c.items {
+		names[i] = item.Name()
+	}
+	return names
+}
+
+// Members returns names of the provider or providers of its fallback chain.
+func Members(p named) []string {
+	if c, ok := p.(interface{ members() []string }); ok {
+		return c.members()
+	}
+	return []string{p.Name()}
+}
+
+// HasOffline returns true if the dictionary or some provider of its fallback chain uses local dictionaries.
+func HasOffline(d Dictionary) bool {
+	return slices.Contains(Members(d), Offline)
+}
+
+// key returns a health tracker key of the provider.
+func (c *chain[T]) key(item T) string {
+	return c.capability + ":" + item.Name()
+}
+
+// order returns providers without recent failures first, failed ones are used as the last resort.
+func (c *chain[T]) order() []T {
+	items := slices.Clone(c.items)
+	slices.SortStableFunc(items, func(a, b T) int {
+		switch available := c.tracker.Available(c.key(a)); {
+		case available == c.tracker.Available(c.key(b)):
+			return 0
+		case available:
+			return -1
+		default:
+			return 1
+		}
+	})
+	return items
+}
+
+// call calls providers in order until one of them answers. The next provider is used
+// only on transport or server side errors or unsupported requests, other errors are returned as is.
+func call[T named, R any](ctx context.Context, c *chain[T], fn func(T) (R, error)) (R, error) {
+	var (
+		result R
+		errs   []error
+	)
+
+	for _, item := range c.order() {
+		response, err := fn(item)
+		if err == nil {
+			c.succeeded(item)
+			answered(ctx, c.capability, item.Name())
+			return response, nil
+		}
+
+		if !cloud.Unavailable(err) {
+			return result, err
+		}
+
+		errs = append(errs, c.failed(item, err))
+	}
+
+	return result, fmt.Errorf("all %s providers are unavailable: %w", c.capability, errors.Join(errs...))
+}
+
+// collect calls all available providers and returns their responses in order of the chain,
+// like languages lists which are merged because any provider can answer later requests.
+// Providers with recent failures are used only if no other provider has answered.
+func collect[T named, R any](c *chain[T], fn func(T) (R, error)) ([]R, error) {
+	var (
+		results []R
+		errs    []error
+	)
+
+	for _, item := range c.order() {
+		if len(results) > 0 && !c.tracker.Available(c.key(item)) {
+			continue
+		}
+
+		response, err := fn(item)
+		if err == nil {
+			c.succeeded(item)
+			results = append(results, response)
+			continue
+		}
+
+		if !cloud.Unavailable(err) {
+			return nil, err
+		}
+
+		errs = append(errs, c.failed(item, err))
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("all %s providers are unavailable: %w", c.capability, errors.Join(errs...))
+	}
+
+	return results, nil
+}
+
+// succeeded resets failures of the provider.
+func (c *chain[T]) succeeded(item T) {
+	if e := c.tracker.Success(c.key(item)); e != nil {
+		c.logger.Printf("health cache: %v", e)
+	}
+}
+
+// failed saves the failure of unavailable provider and returns its error labeled by the provider name.
+// Providers which don't support the request are not marked as failed.
+func (c *chain[T]) failed(item T, err error) error {
+	c.logger.Printf("%s provider %q is unavailable: %v", c.capability, item.Name(), err)
+
+	// the provider works, but not for this request
+	if !errors.Is(err, cloud.ErrUnsupported) {
+		if e := c.tracker.Fail(c.key(item)); e != nil {
+			c.logger.Printf("health cache: %v", e)
+		}
+	}
+
+	return fmt.Errorf("%s: %w", item.Name(), err)
+}
+
+// pairError returns an unsupported request error if the provider rejected the request by a client error (4xx)
+// and the language pair is absent in its own languages list, so the next provider of the chain can answer.
+// Other errors are returned as is, languages are loaded only after rejected requests.
+func pairError(err error, supported func() (bool, error)) error {
+	var statusErr *cloud.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code < http.StatusBadRequest || statusErr.Code >= http.StatusInternalServerError {
+		return err
+	}
+
+	if ok, e := supported(); e != nil || ok {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", err, cloud.ErrUnsupported)
+}
+
+// translatorChain is a fallback chain of translators.
+type translatorChain struct {
+	*chain[Translator]
+}
+
+// Translate is an implementation of Translate() method for Translator interface.
+// A provider which rejects a language pair absent in its languages list is skipped.
+func (c translatorChain) Translate(ctx context.Context, r *translation.Request) (*translation.Response, error) {
+	return call(ctx, c.chain, func(t Translator) (*translation.Response, error) {
+		response, err := t.Translate(ctx, r)
+		if err != nil {
+			return nil, pairError(err, func() (bool, error) {
+				languages, e := t.Languages(ctx)
+				if e != nil {
+					return false, e
+				}
+				// the source language is detected by the provider if it's empty
+				return languages.Contains(cmp.Or(r.SourceLanguageCode, r.TargetLanguageCode), r.TargetLanguageCode), nil
+			})
+		}
+		return response, nil
+	})
+}
+
+// Detect is an implementation of Detect() method for Translator interface.
+func (c translatorChain) Detect(ctx context.Context, text string) (string, error) {
+	return call(ctx, c.chain, func(t Translator) (string, error) {
+		return t.Detect(ctx, text)
+	})
+}
+
+// Languages is an implementation of Languages() method for Translator interface.
+// It returns languages of all providers, names of the first providers are used for the same codes.
+func (c translatorChain) Languages(ctx context.Context) (*translation.Languages, error) {
+	lists, err := collect(c.chain, func(t Translator) (*translation.Languages, error) {
+		return t.Languages(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		items []translation.Language
+		codes = make(map[string]struct{})
+	)
+
+	for _, languages := range lists {
+		for _, language := range languages.Languages {
+			code := strings.ToLower(language.Code)
+			if _, ok := codes[code]; !ok {
+				codes[code] = struct{}{}
+				items = append(items, language)
+			}
+		}
+	}
+
+	return translation.NewLanguages(items), nil
+}
+
+// dictionaryChain is a fallback chain of dictionaries.
+type dictionaryChain struct {
+	*chain[Dictionary]
+}
+
+// Lookup is an implementation of Lookup() method for Dictionary interface.
+// A provider which rejects a language pair absent in its languages list is skipped.
+func (c dictionaryChain) Lookup(ctx context.Context, r *dictionary.Request) (*dictionary.Response, error) {
+	return call(ctx, c.chain, func(d Dictionary) (*dictionary.Response, error) {
+		response, err := d.Lookup(ctx, r)
+		if err != nil {
+			return nil, pairError(err, func() (bool, error) {
+				pairs, e := d.Languages(ctx)
+				if e != nil {
+					return false, e
+				}
+				return pairs.Contains(r.SourceLanguageCode, r.TargetLanguageCode), nil
+			})
+		}
+		return response, nil
+	})
+}
+
+// Languages is an implementation of Languages() method for Dictionary interface.
+// It returns language pairs of all providers.
+func (c dictionaryChain) Languages(ctx context.Context) (*dictionary.Languages, error) {
+	lists, err := collect(c.chain, func(d Dictionary) (*dictionary.Languages, error) {
+		return d.Languages(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs dictionary.Languages
+	for _, languages := range lists {
+		pairs = append(pairs, *languages...)
+	}
+
+	pairs.Sort()
+	pairs = slices.Compact(pairs)
+	return &pairs, nil
+}
+
+// spellCheckerChain is a fallback chain of spelling checkers.
+type spellCheckerChain struct {
+	*chain[SpellChecker]
+}
+
+// Check is an implementation of Check() method for SpellChecker interface.
+func (c spellCheckerChain) Check(ctx context.Context, lang, text string) (*spelling.Response, error) {
+	return call(ctx, c.chain, func(s SpellChecker) (*spelling.Response, error) {
+		return s.Check(ctx, lang, text)
+	})
+}
+
+// Languages is an implementation of Languages() method for SpellChecker interface.
+// It returns languages of all providers.
+func (c spellCheckerChain) Languages() []string {
+	var languages []string
+	for _, item := range c.items {
+		languages = append(languages, item.Languages()...)
+	}
+
+	slices.Sort(languages)
+	return slices.Compact(languages)
+}
+
+// build returns providers by names, the default provider is used if names are empty.
+func build[T named](names []string, fn func(string) (T, error)) ([]T, error) {
+	if len(names) == 0 {
+		names = []string{""}
+	}
+
+	items := make([]T, len(names))
+	for i, name := range names {
+		item, err := fn(name)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+// NewTranslatorChain returns a translator by providers names,
+// several providers are combined to a fallback chain.
+func NewTranslatorChain(names []string, client *http.Client, cfg *config.Config, tracker *health.Tracker) (Translator, error) {
+	items, err := build(names, func(name string) (Translator, error) { return NewTranslator(name, client, cfg) })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return translatorChain{&chain[Translator]{capabilityTranslation, items, tracker, cfg.Logger}}, nil
+}
+
+// NewDictionaryChain returns a dictionary by providers names,
+// several providers are combined to a fallback chain.
+func NewDictionaryChain(names []string, client *http.Client, cfg *config.Config, tracker *health.Tracker) (Dictionary, error) {
+	items, err := build(names, func(name string) (Dictionary, error) { return NewDictionary(name, client, cfg) })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return dictionaryChain{&chain[Dictionary]{capabilityDictionary, items, tracker, cfg.Logger}}, nil
+}
+
+// NewSpellCheckerChain returns a spelling checker by providers names,
+// several providers are combined to a fallback chain.
+func NewSpellCheckerChain(names []string, client *http.Client, cfg *config.Config, tracker *health.Tracker) (SpellChecker, error) {
+	items, err := build(names, func(name string) (SpellChecker, error) { return NewSpellChecker(name, client, cfg) })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return spellCheckerChain{&chain[SpellChecker]{capabilitySpelling, items, tracker, cfg.Logger}}, nil
+}
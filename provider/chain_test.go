@@ -0,0 +1,398 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/health"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// fakeTranslator returns its name as translation or an error.
+type fakeTranslator struct {
+	name      string
+	err       error
+	rejected  error // error of translations only
+	calls     int
+	languages []translation.Language
+}
+
+func (f *fakeTranslator) Name() string {
+	return f.name
+}
+
+func (f *fakeTranslator) Translate(context.Context, *translation.Request) (*translation.Response, error) {
+	f.calls++
+	if err := cmp.Or(f.err, f.rejected); err != nil {
+		return nil, err
+	}
+	return &translation.Response{Translations: []translation.ResponseItem{{Text: f.name}}}, nil
+}
+
+func (f *fakeTranslator) Detect(context.Context, string) (string, error) {
+	f.calls++
+	return "en", f.err
+}
+
+func (f *fakeTranslator) Languages(context.Context) (*translation.Languages, error) {
+	f.calls++
+	return translation.NewLanguages(f.languages), f.err
+}
+
+// fakeDictionary supports only its language pairs.
+type fakeDictionary struct {
+	name     string
+	pairs    dictionary.Languages
+	err      error
+	rejected error // error of lookups only
+}
+
+func (f *fakeDictionary) Name() string {
+	return f.name
+}
+
+func (f *fakeDictionary) Lookup(context.Context, *dictionary.Request) (*dictionary.Response, error) {
+	return &dictionary.Response{}, cmp.Or(f.err, f.rejected)
+}
+
+func (f *fakeDictionary) Languages(context.Context) (*dictionary.Languages, error) {
+	return &f.pairs, f.err
+}
+
+// fakeSpeller supports only its languages.
+type fakeSpeller struct {
+	name      string
+	languages []string
+}
+
+func (f *fakeSpeller) Name() string {
+	return f.name
+}
+
+func (f *fakeSpeller) Check(context.Context, string, string) (*spelling.Response, error) {
+	return &spelling.Response{}, nil
+}
+
+func (f *fakeSpeller) Languages() []string {
+	return f.languages
+}
+
+func TestTranslatorChain(t *testing.T) {
+	var (
+		unavailable = &cloud.StatusError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+		forbidden   = &cloud.StatusError{Code: http.StatusForbidden, Status: "403 Forbidden"}
+		ctx         = context.Background()
+	)
+
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := &fakeTranslator{name: "first", err: unavailable}, &fakeTranslator{name: "second"}
+	c := translatorChain{&chain[Translator]{capabilityTranslation, []Translator{first, second}, tracker, logger}}
+
+	if name := c.Name(); name != "first,second" {
+		t.Errorf("unexpected name %q", name)
+	}
+
+	var answers []string
+	tracedCtx := WithTrace(ctx, &Trace{Answered: func(capability, name string) {
+		answers = append(answers, capability+":"+name)
+	}})
+
+	response, err := c.Translate(tracedCtx, &translation.Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := response.String(); s != "second" {
+		t.Errorf("unexpected translation %q", s)
+	}
+
+	if !slices.Equal(answers, []string{"translation:second"}) {
+		t.Errorf("unexpected answers %q", answers)
+	}
+
+	// failed provider is used after available ones during cool-down
+	if _, err = c.Detect(ctx, "text"); err != nil {
+		t.Fatal(err)
+	}
+
+	if first.calls != 1 || second.calls != 2 {
+		t.Errorf("unexpected calls %d and %d", first.calls, second.calls)
+	}
+
+	// not transport or server error is returned as is
+	second.err = forbidden
+	if _, err = c.Languages(ctx); !errors.Is(err, forbidden) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if first.calls != 1 {
+		t.Errorf("unavailable provider is called %d times", first.calls)
+	}
+
+	second.err = unavailable
+	_, err = c.Translate(ctx, &translation.Request{})
+	if err == nil || !strings.HasPrefix(err.Error(), "all translation providers are unavailable: second: request status 503") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if first.calls != 2 || second.calls != 4 {
+		t.Errorf("unexpected calls %d and %d", first.calls, second.calls)
+	}
+}
+
+func TestTranslatorChain_Languages(t *testing.T) {
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := &fakeTranslator{name: "first", languages: []translation.Language{{Code: "en", Name: "English"}, {Code: "ru", Name: "Russian"}}}
+	second := &fakeTranslator{name: "second", languages: []translation.Language{{Code: "EN", Name: "english"}, {Code: "eo", Name: "Esperanto"}}}
+	c := translatorChain{&chain[Translator]{capabilityTranslation, []Translator{first, second}, tracker, logger}}
+
+	languages, err := c.Languages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []translation.Language{{Code: "en", Name: "English"}, {Code: "ru", Name: "Russian"}, {Code: "eo", Name: "Esperanto"}}
+	if !slices.Equal(languages.Languages, expected) {
+		t.Errorf("expected %v, got %v", expected, languages.Languages)
+	}
+
+	// a failed provider is skipped during cool-down if other providers answered
+	second.err = &cloud.StatusError{Code: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+	if languages, err = c.Languages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(languages.Languages) != 2 || tracker.Available(c.key(second)) {
+		t.Errorf("unexpected languages %v", languages.Languages)
+	}
+
+	if languages, err = c.Languages(context.Background()); err != nil || second.calls != 2 {
+		t.Errorf("unexpected calls %d, error %v", second.calls, err)
+	}
+}
+
+func TestDictionaryChain_Languages(t *testing.T) {
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []Dictionary{
+		&fakeDictionary{name: "first", pairs: dictionary.Languages{"en-ru", "ru-en"}},
+		&fakeDictionary{name: "second", err: fmt.Errorf("no pairs: %w", cloud.ErrUnsupported)},
+		&fakeDictionary{name: "third", pairs: dictionary.Languages{"en-de", "en-ru"}},
+	}
+	c := dictionaryChain{&chain[Dictionary]{capabilityDictionary, items, tracker, logger}}
+
+	pairs, err := c.Languages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (dictionary.Languages{"en-de", "en-ru", "ru-en"}); !slices.Equal(*pairs, expected) {
+		t.Errorf("expected %v, got %v", expected, *pairs)
+	}
+
+	items[0].(*fakeDictionary).err = errors.New("forbidden")
+	if _, err = c.Languages(context.Background()); err == nil || err.Error() != "forbidden" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestTranslatorChain_deadline(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "health.json")
+
+	tracker, err := health.New(fileName, time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// the caller's deadline expires during the request
+	_, timeout := cloud.Request(ctx, s.Client(), nil, s.URL, "", "test", true, logger)
+	if !errors.Is(timeout, context.DeadlineExceeded) {
+		t.Fatalf("unexpected request error %v", timeout)
+	}
+
+	first, second := &fakeTranslator{name: "first", err: timeout}, &fakeTranslator{name: "second"}
+	c := translatorChain{&chain[Translator]{capabilityTranslation, []Translator{first, second}, tracker, logger}}
+
+	if _, err = c.Translate(ctx, &translation.Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if second.calls != 0 {
+		t.Errorf("next provider is called %d times", second.calls)
+	}
+
+	if _, err = os.Stat(fileName); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("health cache is written: %v", err)
+	}
+}
+
+func TestTranslatorChain_unsupported(t *testing.T) {
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := &fakeTranslator{name: "first", err: fmt.Errorf("no pair: %w", cloud.ErrUnsupported)}
+	c := translatorChain{&chain[Translator]{capabilityTranslation, []Translator{first, &fakeTranslator{name: "second"}}, tracker, logger}}
+
+	response, err := c.Translate(context.Background(), &translation.Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := response.String(); s != "second" {
+		t.Errorf("unexpected translation %q", s)
+	}
+
+	// the provider works for other requests
+	if !tracker.Available(c.key(first)) {
+		t.Error("provider without request support is marked as failed")
+	}
+}
+
+func TestTranslatorChain_unsupportedPair(t *testing.T) {
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		rejected = &cloud.StatusError{Code: http.StatusBadRequest, Status: "400 Bad Request", Body: []byte("unsupported language")}
+		first    = &fakeTranslator{name: "first", rejected: rejected, languages: []translation.Language{{Code: "en"}, {Code: "ru"}}}
+		second   = &fakeTranslator{name: "second", languages: []translation.Language{{Code: "en"}, {Code: "de"}}}
+		c        = translatorChain{&chain[Translator]{capabilityTranslation, []Translator{first, second}, tracker, logger}}
+	)
+
+	// only the second provider supports the pair
+	response, err := c.Translate(context.Background(), &translation.Request{SourceLanguageCode: "en", TargetLanguageCode: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := response.String(); s != "second" {
+		t.Errorf("unexpected translation %q", s)
+	}
+
+	if !tracker.Available(c.key(first)) {
+		t.Error("provider without language pair support is marked as failed")
+	}
+
+	// a client error of the supported pair is not hidden by fallback
+	_, err = c.Translate(context.Background(), &translation.Request{SourceLanguageCode: "en", TargetLanguageCode: "ru"})
+	if !errors.Is(err, rejected) || second.calls != 1 {
+		t.Errorf("unexpected error %v, calls %d", err, second.calls)
+	}
+
+	d := dictionaryChain{&chain[Dictionary]{capabilityDictionary, []Dictionary{
+		&fakeDictionary{name: "first", pairs: dictionary.Languages{"en-ru"}, rejected: rejected},
+		&fakeDictionary{name: "second", pairs: dictionary.Languages{"en-de"}},
+	}, tracker, logger}}
+
+	if _, err = d.Lookup(context.Background(), &dictionary.Request{SourceLanguageCode: "en", TargetLanguageCode: "de"}); err != nil {
+		t.Errorf("unexpected lookup error %v", err)
+	}
+}
+
+func TestSpellCheckerChain(t *testing.T) {
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := spellCheckerChain{&chain[SpellChecker]{
+		capability: capabilitySpelling,
+		items:      []SpellChecker{&fakeSpeller{"a", []string{"ru", "en"}}, &fakeSpeller{"b", []string{"de", "en"}}},
+		tracker:    tracker,
+		logger:     logger,
+	}}
+
+	if languages := c.Languages(); !slices.Equal(languages, []string{"de", "en", "ru"}) {
+		t.Errorf("unexpected languages %q", languages)
+	}
+
+	if _, err = c.Check(context.Background(), "en", "text"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewChain(t *testing.T) {
+	var (
+		client = &http.Client{}
+		cfg    = &config.Config{Logger: logger, LibreTranslate: config.LibreTranslate{URL: "http://localhost:5000"}}
+	)
+
+	tracker, err := health.New("", time.Minute, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := NewTranslatorChain(nil, client, cfg, tracker)
+	if err != nil || translator.Name() != Yandex {
+		t.Errorf("unexpected translator: %v", err)
+	}
+
+	translator, err = NewTranslatorChain([]string{LibreTranslate, Yandex}, client, cfg, tracker)
+	if err != nil || translator.Name() != "libretranslate,yandex" {
+		t.Errorf("unexpected translator: %v", err)
+	}
+
+	dictionary, err := NewDictionaryChain([]string{Yandex}, client, cfg, tracker)
+	if err != nil || dictionary.Name() != Yandex {
+		t.Errorf("unexpected dictionary: %v", err)
+	}
+
+	if _, err = NewDictionaryChain([]string{Yandex, Offline}, client, cfg, tracker); err == nil {
+		t.Error("expected error for not configured offline dictionary")
+	}
+
+	if HasOffline(dictionary) {
+		t.Error("unexpected offline dictionary")
+	}
+
+	cfg.Offline = map[string]string{"en-ru": "en-ru.index"}
+	if dictionary, err = NewDictionaryChain([]string{Offline, Yandex}, client, cfg, tracker); err != nil {
+		t.Fatal(err)
+	}
+
+	if members := Members(dictionary); !HasOffline(dictionary) || !slices.Equal(members, []string{Offline, Yandex}) {
+		t.Errorf("unexpected dictionary members %v", members)
+	}
+
+	speller, err := NewSpellCheckerChain([]string{Yandex, Yandex}, client, cfg, tracker)
+	if err != nil || speller.Name() != "yandex,yandex" {
+		t.Errorf("unexpected spelling checker: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/libre"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// libreTranslator uses LibreTranslate compatible server.
+type libreTranslator struct {
+	client *http.Client
+	cfg    *config.Config
+}
+
+// Name is an implementation of Name() method for Translator interface.
+func (t *libreTranslator) Name() string {
+	return LibreTranslate
+}
+
+// Translate is an implementation of Translate() method for Translator interface.
+func (t *libreTranslator) Translate(ctx context.Context, r *translation.Request) (*translation.Response, error) {
+	return translation.BatchBy(ctx, r, parallelRequests, func(ctx context.Context, request *translation.Request) (*translation.Response, error) {
+		return libre.Translate(ctx, t.client, t.cfg, request)
+	})
+}
+
+// Detect is an implementation of Detect() method for Translator interface.
+func (t *libreTranslator) Detect(ctx context.Context, text string) (string, error) {
+	return libre.DetectLanguage(ctx, t.client, t.cfg, text)
+}
+
+// Languages is an implementation of Languages() method for Translator interface.
+func (t *libreTranslator) Languages(ctx context.Context) (*translation.Languages, error) {
+	return libre.LoadLanguages(ctx, t.client, t.cfg)
+}
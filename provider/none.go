@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/spelling"
+)
+
+// noneDictionary is a disabled dictionary, texts are only translated.
+type noneDictionary struct{}
+
+// Name is an implementation of Name() method for Dictionary interface.
+func (noneDictionary) Name() string {
+	return None
+}
+
+// Lookup is an implementation of Lookup() method for Dictionary interface.
+// Lookups are not supported, so other providers of a chain can be used.
+func (noneDictionary) Lookup(context.Context, *dictionary.Request) (*dictionary.Response, error) {
+	return nil, fmt.Errorf("dictionary is disabled: %w", cloud.ErrUnsupported)
+}
+
+// Languages is an implementation of Languages() method for Dictionary interface.
+func (noneDictionary) Languages(context.Context) (*dictionary.Languages, error) {
+	return &dictionary.Languages{}, nil
+}
+
+// noneSpeller is a disabled spelling check.
+type noneSpeller struct{}
+
+// Name is an implementation of Name() method for SpellChecker interface.
+func (noneSpeller) Name() string {
+	return None
+}
+
+// Check is an implementation of Check() method for SpellChecker interface, no language is supported.
+func (noneSpeller) Check(context.Context, string, string) (*spelling.Response, error) {
+	return nil, nil
+}
+
+// Languages is an implementation of Languages() method for SpellChecker interface.
+func (noneSpeller) Languages() []string {
+	return nil
+}
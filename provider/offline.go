@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/offline"
+)
+
+// offlineDictionary uses local StarDict or dictd files configured by language pairs.
+// Every dictionary is opened and indexed once by the first lookup, its data file stays open.
+type offlineDictionary struct {
+	cfg    *config.Config
+	mu     sync.Mutex
+	opened map[string]*offline.Dictionary // dictionaries by language pairs
+}
+
+// Name is an implementation of Name() method for Dictionary interface.
+func (d *offlineDictionary) Name() string {
+	return Offline
+}
+
+// Lookup is an implementation of Lookup() method for Dictionary interface.
+// Not configured language pairs are not supported, so other providers of a chain can be used.
+func (d *offlineDictionary) Lookup(ctx context.Context, r *dictionary.Request) (*dictionary.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pair := langtag.Base(r.SourceLanguageCode) + "-" + langtag.Base(r.TargetLanguageCode)
+	dict, err := d.open(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	return dict.Lookup(r.Text)
+}
+
+// open returns an opened dictionary of the language pair.
+func (d *offlineDictionary) open(pair string) (*offline.Dictionary, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if dict, ok := d.opened[pair]; ok {
+		return dict, nil
+	}
+
+	fileName, ok := d.pairs()[pair]
+	if !ok {
+		return nil, fmt.Errorf("no offline dictionary for %q: %w", pair, cloud.ErrUnsupported)
+	}
+
+	d.cfg.Logger.Printf("open offline dictionary %q", fileName)
+	dict, err := offline.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("offline dictionary %q: %w", pair, err)
+	}
+
+	if d.opened == nil {
+		d.opened = make(map[string]*offline.Dictionary)
+	}
+
+	d.opened[pair] = dict
+	return dict, nil
+}
+
+// Languages is an implementation of Languages() method for Dictionary interface.
+func (d *offlineDictionary) Languages(context.Context) (*dictionary.Languages, error) {
+	pairs := d.pairs()
+	languages := make(dictionary.Languages, 0, len(pairs))
+
+	for pair := range pairs {
+		languages = append(languages, pair)
+	}
+
+	languages.Sort()
+	return &languages, nil
+}
+
+// pairs returns dictionary files by lower case language pairs like "en-ru".
+func (d *offlineDictionary) pairs() map[string]string {
+	pairs := make(map[string]string, len(d.cfg.Offline))
+
+	for pair, fileName := range d.cfg.Offline {
+		pairs[strings.ToLower(pair)] = fileName
+	}
+
+	return pairs
+}
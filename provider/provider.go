@@ -0,0 +1,100 @@
+// Package provider defines translation, dictionary and spelling check backends.
+// Yandex services are used by default, other backends are selected in configuration.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// Names of providers.
+const (
+	// Yandex is a name of Yandex Translate, Dictionary and Speller provider.
+	Yandex = "yandex"
+	// LibreTranslate is a name of LibreTranslate compatible translation provider.
+	LibreTranslate = "libretranslate"
+	// Offline is a name of local StarDict and dictd dictionaries provider.
+	Offline = "offline"
+	// None is a name of disabled dictionary or spelling check, texts are not sent to any service.
+	None = "none"
+)
+
+// Translator translates texts and detects their languages.
+type Translator interface {
+	// Name returns a provider name.
+	Name() string
+	// Translate translates texts of any length keeping their order,
+	// request languages and format are used, other fields are set by provider.
+	Translate(ctx context.Context, r *translation.Request) (*translation.Response, error)
+	// Detect returns a language code of the text.
+	Detect(ctx context.Context, text string) (string, error)
+	// Languages returns supported translation languages.
+	Languages(ctx context.Context) (*translation.Languages, error)
+}
+
+// Dictionary looks up dictionary articles of words and phrases.
+type Dictionary interface {
+	// Name returns a provider name.
+	Name() string
+	// Lookup returns a dictionary article, request key is set by provider.
+	Lookup(ctx context.Context, r *dictionary.Request) (*dictionary.Response, error)
+	// Languages returns supported dictionary language pairs.
+	Languages(ctx context.Context) (*dictionary.Languages, error)
+}
+
+// SpellChecker checks spelling of texts.
+type SpellChecker interface {
+	// Name returns a provider name.
+	Name() string
+	// Check returns spelling errors of the text, the result is nil if the language is not supported.
+	Check(ctx context.Context, lang, text string) (*spelling.Response, error)
+	// Languages returns supported spelling check languages.
+	Languages() []string
+}
+
+// NewTranslator returns a translator by provider name, Yandex is used if the name is empty.
+func NewTranslator(name string, client *http.Client, cfg *config.Config) (Translator, error) {
+	switch name {
+	case "", Yandex:
+		return &yandexTranslator{client: client, cfg: cfg}, nil
+	case LibreTranslate:
+		if cfg.LibreTranslate.URL == "" {
+			return nil, fmt.Errorf("LibreTranslate URL is not configured")
+		}
+		return &libreTranslator{client: client, cfg: cfg}, nil
+	}
+	return nil, fmt.Errorf("unknown translation provider %q", name)
+}
+
+// NewDictionary returns a dictionary by provider name, Yandex is used if the name is empty.
+func NewDictionary(name string, client *http.Client, cfg *config.Config) (Dictionary, error) {
+	switch name {
+	case "", Yandex:
+		return &yandexDictionary{client: client, cfg: cfg}, nil
+	case Offline:
+		if len(cfg.Offline) == 0 {
+			return nil, fmt.Errorf("offline dictionaries are not configured")
+		}
+		return &offlineDictionary{cfg: cfg}, nil
+	case None:
+		return noneDictionary{}, nil
+	}
+	return nil, fmt.Errorf("unknown dictionary provider %q", name)
+}
+
+// NewSpellChecker returns a spelling checker by provider name, Yandex is used if the name is empty.
+func NewSpellChecker(name string, client *http.Client, cfg *config.Config) (SpellChecker, error) {
+	switch name {
+	case "", Yandex:
+		return &yandexSpeller{client: client, cfg: cfg}, nil
+	case None:
+		return noneSpeller{}, nil
+	}
+	return nil, fmt.Errorf("unknown spelling provider %q", name)
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+)
+
+var logger = log.New(os.Stdout, "TEST ", log.Lmicroseconds|log.Lshortfile)
+
+func TestNew(t *testing.T) {
+	var (
+		client = &http.Client{}
+		cfg    = &config.Config{}
+	)
+
+	for _, name := range []string{"", Yandex} {
+		translator, err := NewTranslator(name, client, cfg)
+		if err != nil || translator.Name() != Yandex {
+			t.Errorf("unexpected translator for %q: %v", name, err)
+		}
+
+		dictionary, err := NewDictionary(name, client, cfg)
+		if err != nil || dictionary.Name() != Yandex {
+			t.Errorf("unexpected dictionary for %q: %v", name, err)
+		}
+
+		speller, err := NewSpellChecker(name, client, cfg)
+		if err != nil || speller.Name() != Yandex || len(speller.Languages()) == 0 {
+			t.Errorf("unexpected spelling checker for %q: %v", name, err)
+		}
+	}
+
+	if _, err := NewTranslator(LibreTranslate, client, cfg); err == nil || err.Error() != "LibreTranslate URL is not configured" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.LibreTranslate.URL = "http://localhost:5000"
+	if translator, err := NewTranslator(LibreTranslate, client, cfg); err != nil || translator.Name() != LibreTranslate {
+		t.Errorf("unexpected LibreTranslate translator: %v", err)
+	}
+
+	if _, err := NewDictionary(Offline, client, cfg); err == nil || err.Error() != "offline dictionaries are not configured" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	dict, err := NewDictionary(None, client, cfg)
+	if err != nil || dict.Name() != None {
+		t.Fatalf("unexpected none dictionary: %v", err)
+	}
+
+	if _, err = dict.Lookup(context.Background(), &dictionary.Request{Text: "time"}); !errors.Is(err, cloud.ErrUnsupported) {
+		t.Errorf("unexpected none dictionary lookup error: %v", err)
+	}
+
+	if pairs, e := dict.Languages(context.Background()); e != nil || len(*pairs) != 0 {
+		t.Errorf("unexpected none dictionary languages %v: %v", pairs, e)
+	}
+
+	speller, err := NewSpellChecker(None, client, cfg)
+	if err != nil || speller.Name() != None || len(speller.Languages()) != 0 {
+		t.Fatalf("unexpected none spelling checker: %v", err)
+	}
+
+	if r, e := speller.Check(context.Background(), "en", "time"); r != nil || e != nil {
+		t.Errorf("unexpected none spelling check %v: %v", r, e)
+	}
+
+	if _, err := NewTranslator("unknown", client, cfg); err == nil || err.Error() != `unknown translation provider "unknown"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := NewDictionary("unknown", client, cfg); err == nil || err.Error() != `unknown dictionary provider "unknown"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := NewSpellChecker("unknown", client, cfg); err == nil || err.Error() != `unknown spelling provider "unknown"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestOfflineDictionary(t *testing.T) {
+	dir := t.TempDir()
+
+	// dictd database with one 22 bytes article, "W" is base64 encoded size
+	if err := os.WriteFile(filepath.Join(dir, "en-ru.index"), []byte("apple\tA\tW\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "en-ru.dict"), []byte("apple\n   яблоко\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Offline: map[string]string{"EN-RU": filepath.Join(dir, "en-ru.index"), "de-ru": filepath.Join(dir, "de-ru.ifo")},
+		Logger:  logger,
+	}
+
+	d, err := NewDictionary(Offline, &http.Client{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	languages, err := d.Languages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := languages.String(); s != "Dictionary languages:\nde-ru, en-ru" {
+		t.Errorf("unexpected languages %q", s)
+	}
+
+	response, err := d.Lookup(context.Background(), &dictionary.Request{Text: "Apple", SourceLanguageCode: "en-GB", TargetLanguageCode: "ru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := response.String(); s != "apple\n\tяблоко" {
+		t.Errorf("unexpected response %q", s)
+	}
+
+	// opened dictionary is not read again
+	if err = os.Remove(filepath.Join(dir, "en-ru.index")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = d.Lookup(context.Background(), &dictionary.Request{Text: "apple", SourceLanguageCode: "en", TargetLanguageCode: "ru"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = d.Lookup(context.Background(), &dictionary.Request{Text: "Hund", SourceLanguageCode: "de", TargetLanguageCode: "en"})
+	if err == nil || err.Error() != `no offline dictionary for "de-en": not supported by the service` {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if !cloud.Unavailable(err) {
+		t.Error("not configured pair must be unavailable for providers chain")
+	}
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/dictionary"
+	"github.com/z0rr0/ytapigo/spelling"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+// parallelRequests is a max number of concurrent translation requests for long texts.
+const parallelRequests = 4
+
+// yandexTranslator uses Yandex Cloud Translate API.
+type yandexTranslator struct {
+	client *http.Client
+	cfg    *config.Config
+}
+
+// Name is an implementation of Name() method for Translator interface.
+func (t *yandexTranslator) Name() string {
+	return Yandex
+}
+
+// Translate is an implementation of Translate() method for Translator interface.
+func (t *yandexTranslator) Translate(ctx context.Context, r *translation.Request) (*translation.Response, error) {
+	request := *r
+	request.FolderID = t.cfg.Translation.FolderID
+
+	return translation.Batch(ctx, t.client, t.cfg, &request, parallelRequests)
+}
+
+// Detect is an implementation of Detect() method for Translator interface.
+func (t *yandexTranslator) Detect(ctx context.Context, text string) (string, error) {
+	return translation.DetectLanguage(ctx, t.client, t.cfg, text)
+}
+
+// Languages is an implementation of Languages() method for Translator interface.
+func (t *yandexTranslator) Languages(ctx context.Context) (*translation.Languages, error) {
+	return translation.LoadLanguages(ctx, t.client, t.cfg)
+}
+
+// yandexDictionary uses Yandex Dictionary API.
+type yandexDictionary struct {
+	client *http.Client
+	cfg    *config.Config
+}
+
+// Name is an implementation of Name() method for Dictionary interface.
+func (d *yandexDictionary) Name() string {
+	return Yandex
+}
+
+// Lookup is an implementation of Lookup() method for Dictionary interface.
+func (d *yandexDictionary) Lookup(ctx context.Context, r *dictionary.Request) (*dictionary.Response, error) {
+	request := *r
+	request.Key = d.cfg.Dictionary
+
+	return dictionary.Translate(ctx, d.client, d.cfg, &request)
+}
+
+// Languages is an implementation of Languages() method for Dictionary interface.
+func (d *yandexDictionary) Languages(ctx context.Context) (*dictionary.Languages, error) {
+	return dictionary.LoadLanguages(ctx, d.client, d.cfg)
+}
+
+// yandexSpeller uses Yandex Speller API.
+type yandexSpeller struct {
+	client *http.Client
+	cfg    *config.Config
+}
+
+// Name is an implementation of Name() method for SpellChecker interface.
+func (s *yandexSpeller) Name() string {
+	return Yandex
+}
+
+// Check is an implementation of Check() method for SpellChecker interface.
+func (s *yandexSpeller) Check(ctx context.Context, lang, text string) (*spelling.Response, error) {
+	return spelling.Request(ctx, s.client, lang, text, s.cfg)
+}
+
+// Languages is an implementation of Languages() method for SpellChecker interface.
+func (s *yandexSpeller) Languages() []string {
+	return spelling.Languages()
+}
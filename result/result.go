@@ -19,6 +19,22 @@ type Translation interface {
 	Exists() bool
 }
 
+// Labeled is a translation with a label, like its target language.
+type Labeled struct {
+	Label       string
+	Translation Translation
+}
+
+// Exists is an implementation of Exists() method for Labeled.
+func (l *Labeled) Exists() bool {
+	return l.Translation != nil && l.Translation.Exists()
+}
+
+// String is an implementation of String() method for Labeled.
+func (l *Labeled) String() string {
+	return "[" + l.Label + "]\n" + l.Translation.String()
+}
+
 // Item is a translation item with priority and error.
 type Item struct {
 	Translation Translation
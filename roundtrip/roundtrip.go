@@ -0,0 +1,144 @@
+// Package roundtrip compares an original text with its back-translation.
+// Diverging words are highlighted by asterisks, similarity is based on Levenshtein distance of normalized texts.
+package roundtrip
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/z0rr0/ytapigo/memory"
+)
+
+// ColumnWidth is a width of original and back-translation columns.
+const ColumnWidth = 38
+
+// Result is a round-trip translation result.
+type Result struct {
+	Original    string
+	Translation string
+	Back        string
+}
+
+// Exists is an implementation of Exists() method for Result.
+func (r *Result) Exists() bool {
+	return r.Translation != "" || r.Back != ""
+}
+
+// String is an implementation of String() method for Result.
+func (r *Result) String() string {
+	original, back := Highlight(r.Original, r.Back)
+
+	var b strings.Builder
+	b.WriteString(r.Translation)
+	b.WriteString("\nRound-trip:")
+
+	left, right := wrap(original, ColumnWidth), wrap(back, ColumnWidth)
+	for i := range max(len(left), len(right)) {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+
+		padding := strings.Repeat(" ", max(ColumnWidth-utf8.RuneCountInString(l), 0))
+		b.WriteString(strings.TrimRight("\n\t"+l+padding+" | "+r, " "))
+	}
+
+	fmt.Fprintf(&b, "\n\tsimilarity: %.0f%%", r.Similarity()*100)
+	return b.String()
+}
+
+// Similarity returns a similarity of original text and back-translation from 0 to 1.
+func (r *Result) Similarity() float64 {
+	return memory.Similarity(memory.Normalize(r.Original), memory.Normalize(r.Back))
+}
+
+// word returns a comparable form of the word: lower case without punctuation.
+func word(s string) string {
+	return strings.ToLower(strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
+
+// common returns flags of words which are a part of the longest common subsequence of a and b.
+func common(a, b []string) ([]bool, []bool) {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if word(a[i]) == word(b[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	inA, inB := make([]bool, len(a)), make([]bool, len(b))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case word(a[i]) == word(b[j]):
+			inA[i], inB[j] = true, true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return inA, inB
+}
+
+// Highlight returns texts where words which are not common for both ones are marked by asterisks.
+func Highlight(a, b string) (string, string) {
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	inA, inB := common(wordsA, wordsB)
+
+	return mark(wordsA, inA), mark(wordsB, inB)
+}
+
+// mark joins words, not common ones are marked.
+func mark(words []string, isCommon []bool) string {
+	items := make([]string, len(words))
+	for i, w := range words {
+		if isCommon[i] || word(w) == "" {
+			items[i] = w
+		} else {
+			items[i] = "*" + w + "*"
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+// wrap splits text to lines not longer than width if it is possible.
+func wrap(s string, width int) []string {
+	var (
+		lines   []string
+		current string
+	)
+
+	for _, w := range strings.Fields(s) {
+		if current != "" && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(w) > width {
+			lines = append(lines, current)
+			current = w
+			continue
+		}
+
+		if current == "" {
+			current = w
+		} else {
+			current += " " + w
+		}
+	}
+
+	return append(lines, current)
+}
@@ -0,0 +1,56 @@
+package roundtrip
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		a, b       string
+		expectedA  string
+		expectedB  string
+		similarity float64
+	}{
+		{similarity: 1},
+		{a: "Hello, world!", b: "hello world", expectedA: "Hello, world!", expectedB: "hello world", similarity: 1 - 2.0/13},
+		{
+			a:          "The quick brown fox jumps",
+			b:          "The fast brown fox is jumping",
+			expectedA:  "The *quick* brown fox *jumps*",
+			expectedB:  "The *fast* brown fox *is* *jumping*",
+			similarity: 1 - 11.0/29,
+		},
+		{a: "a - b", b: "a b", expectedA: "a - b", expectedB: "a b", similarity: 0.6},
+	}
+
+	for i, tc := range testCases {
+		a, b := Highlight(tc.a, tc.b)
+		if a != tc.expectedA || b != tc.expectedB {
+			t.Errorf("case %d: expected %q and %q, got %q and %q", i, tc.expectedA, tc.expectedB, a, b)
+		}
+
+		r := &Result{Original: tc.a, Back: tc.b}
+		if s := r.Similarity(); s != tc.similarity {
+			t.Errorf("case %d: expected similarity %v, got %v", i, tc.similarity, s)
+		}
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	r := &Result{
+		Original:    "Customers can return goods within thirty days",
+		Translation: "Покупатели могут вернуть товар в течение тридцати дней",
+		Back:        "Buyers can return the goods within thirty days",
+	}
+
+	expected := "Покупатели могут вернуть товар в течение тридцати дней\nRound-trip:\n" +
+		"\t*Customers* can return goods within    | *Buyers* can return *the* goods within\n" +
+		"\tthirty days                            | thirty days\n" +
+		"\tsimilarity: 80%"
+
+	if s := r.String(); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+
+	if !r.Exists() || (&Result{}).Exists() {
+		t.Error("unexpected exists result")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"slices"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/z0rr0/ytapigo/cloud"
 	"github.com/z0rr0/ytapigo/config"
+	"github.com/z0rr0/ytapigo/langtag"
 )
 
 // URL is API URL for spell check.
@@ -22,6 +24,11 @@ const URL = "https://speller.yandex.net/services/spellservice.json/checkText"
 // pre-defined languages to don't do extra HTTP requests
 var availableLanguages = map[string]struct{}{"en": {}, "ru": {}, "uk": {}}
 
+// Languages returns sorted codes of spelling check languages.
+func Languages() []string {
+	return slices.Sorted(maps.Keys(availableLanguages))
+}
+
 // Item is a type of spell check (from JSON API response).
 type Item struct {
 	Word string   `json:"word"`
@@ -82,8 +89,9 @@ func reader(lang, text string) io.Reader {
 	return strings.NewReader(params.Encode())
 }
 
-// Request does a request to spelling check API.
+// Request does a request to spelling check API, only primary language subtag is used: "en-GB" -> "en".
 func Request(ctx context.Context, client *http.Client, lang, text string, cfg *config.Config) (*Response, error) {
+	lang = langtag.Base(lang)
 	if _, ok := availableLanguages[lang]; !ok {
 		return nil, nil // skip, spelling check is not available for this language
 	}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/z0rr0/ytapigo/config"
@@ -42,4 +43,19 @@ func TestRequest(t *testing.T) {
 	if respString := resp.String(); respString != expected {
 		t.Errorf("expected: %q , got: %q", expected, respString)
 	}
+
+	// BCP-47 tags are checked by primary language
+	if resp, err = Request(context.Background(), s.Client(), "ru-RU", "малоко", cfg); err != nil || resp == nil {
+		t.Errorf("unexpected response %v for tag: %v", resp, err)
+	}
+
+	if resp, err = Request(context.Background(), s.Client(), "zh-Hans", "你好", cfg); err != nil || resp != nil {
+		t.Errorf("unexpected response %v for not supported language: %v", resp, err)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	if languages := Languages(); !slices.Equal(languages, []string{"en", "ru", "uk"}) {
+		t.Errorf("unexpected languages: %v", languages)
+	}
 }
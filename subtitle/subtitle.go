@@ -0,0 +1,273 @@
+// Package subtitle implements translation of SRT and WebVTT subtitles.
+// Indices, timings, cue settings and styling tags are kept as is,
+// multi-line cues are merged for translation and wrapped to a line width.
+package subtitle
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/z0rr0/ytapigo/markup"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+const (
+	// DefaultWidth is a default max line length of translated cues.
+	DefaultWidth = 42
+
+	// bom is UTF-8 byte order mark
+	bom = "\ufeff"
+)
+
+var (
+	// tags are HTML-like styling tags, VTT timestamps, voice and class spans, and ASS overrides like {\an8}
+	tagRegexp     = regexp.MustCompile(`</?[A-Za-z][^<>]*>|<\d[^<>]*>|\{\\[^{}]*\}`)
+	tagNameRegexp = regexp.MustCompile(`^</?([A-Za-z]+)`)
+)
+
+// block is a part of subtitles file separated by empty lines.
+// Blocks without timing line (like WEBVTT header, NOTE or STYLE) are kept as is.
+type block struct {
+	header []string   // cue identifier and timing lines
+	groups [][]string // cue text lines grouped by speakers
+	raw    []string   // not cue lines
+}
+
+// text is a translatable text of a cue lines group.
+type text struct {
+	block *block
+	group int
+	value string
+	tags  *markup.Text
+}
+
+// splitBlocks splits lines to blocks by empty lines.
+// It returns empty lines before the first block, the blocks and empty lines after every block.
+func splitBlocks(lines []string) (string, []*block, []string) {
+	var (
+		prefix string
+		blocks []*block
+		spaces []string
+		b      *block
+	)
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			if b == nil {
+				b = &block{}
+				blocks = append(blocks, b)
+				spaces = append(spaces, "")
+			}
+			b.raw = append(b.raw, line)
+			continue
+		}
+
+		b = nil
+		if n := len(spaces); n > 0 {
+			spaces[n-1] += line + "\n"
+		} else {
+			prefix += line + "\n"
+		}
+	}
+
+	for _, b = range blocks {
+		b.parse()
+	}
+
+	return prefix, blocks, spaces
+}
+
+// parse splits block lines to header and cue text groups, a new group starts from a speaker dash "- ",
+// so texts like "-5 degrees" are not split.
+func (b *block) parse() {
+	timing := -1
+	for i, line := range b.raw {
+		if strings.Contains(line, "-->") {
+			timing = i
+			break
+		}
+	}
+
+	if timing < 0 || strings.HasPrefix(b.raw[0], "NOTE") {
+		return // not a cue
+	}
+
+	b.header = slices.Clone(b.raw[:timing+1])
+	for _, line := range b.raw[timing+1:] {
+		if len(b.groups) == 0 || strings.HasPrefix(line, "- ") {
+			b.groups = append(b.groups, nil)
+		}
+		b.groups[len(b.groups)-1] = append(b.groups[len(b.groups)-1], line)
+	}
+	b.raw = nil
+}
+
+// mask converts a cue text to HTML text with protected styling tags.
+func mask(m *markup.Text, value string) string {
+	var (
+		locations = tagRegexp.FindAllStringIndex(value, -1)
+		pairs     = make(map[int]int, len(locations)) // opening tag index -> closing tag index
+		opened    []int
+	)
+
+	for i, loc := range locations {
+		tag := value[loc[0]:loc[1]]
+		name := tagNameRegexp.FindStringSubmatch(tag)
+
+		switch {
+		case name == nil:
+			continue
+		case strings.HasPrefix(tag, "</"):
+			for j := len(opened) - 1; j >= 0; j-- {
+				if n := tagNameRegexp.FindStringSubmatch(value[locations[opened[j]][0]:]); n[1] == name[1] {
+					pairs[opened[j]] = i
+					opened = opened[:j]
+					break
+				}
+			}
+		case !strings.HasSuffix(tag, "/>"):
+			opened = append(opened, i)
+		}
+	}
+
+	var (
+		b       strings.Builder
+		start   int
+		closing = make(map[int]string, len(pairs))
+	)
+
+	for i, loc := range locations {
+		b.WriteString(markup.Escape(value[start:loc[0]]))
+		start = loc[1]
+		tag := value[loc[0]:loc[1]]
+
+		if end, ok := pairs[i]; ok {
+			open, closed := m.Pair(tag, value[locations[end][0]:locations[end][1]])
+			b.WriteString(open)
+			closing[end] = closed
+			continue
+		}
+
+		if closed, ok := closing[i]; ok {
+			b.WriteString(closed)
+			continue
+		}
+
+		b.WriteString(m.Token(tag))
+	}
+
+	b.WriteString(markup.Escape(value[start:]))
+	return b.String()
+}
+
+// visibleLength returns a length of text without tags.
+func visibleLength(s string) int {
+	return utf8.RuneCountInString(tagRegexp.ReplaceAllString(s, ""))
+}
+
+// wrap splits text to lines not longer than width visible characters if it is possible.
+func wrap(s string, width int) []string {
+	words := strings.Fields(s)
+	if width <= 0 || len(words) == 0 {
+		return []string{strings.Join(words, " ")}
+	}
+
+	var (
+		lines   []string
+		current string
+	)
+
+	for _, word := range words {
+		if current != "" && visibleLength(current)+1+visibleLength(word) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+
+		if current == "" {
+			current = word
+		} else {
+			current += " " + word
+		}
+	}
+
+	return append(lines, current)
+}
+
+// Translate translates SRT or WebVTT subtitles data by HTML translation function fn,
+// translated cue lines are wrapped to width characters (0 - no wrapping).
+func Translate(ctx context.Context, data []byte, width int, fn translation.BatchFunc) ([]byte, error) {
+	source := string(data)
+	eol := "\n"
+
+	if strings.Contains(source, "\r\n") {
+		eol = "\r\n"
+		source = strings.ReplaceAll(source, "\r\n", "\n")
+	}
+
+	source = strings.TrimPrefix(source, bom)
+	prefix, blocks, spaces := splitBlocks(strings.Split(strings.TrimSuffix(source, "\n"), "\n"))
+
+	var (
+		texts []*text
+		items []string
+	)
+
+	for _, b := range blocks {
+		for i, group := range b.groups {
+			t := &text{block: b, group: i, value: strings.Join(group, " "), tags: &markup.Text{}}
+			texts = append(texts, t)
+			items = append(items, mask(t.tags, t.value))
+		}
+	}
+
+	translations, err := fn(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(translations) != len(items) {
+		return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(items))
+	}
+
+	for i, t := range texts {
+		value, err := t.tags.Restore(translations[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed cue %q: %w", t.value, err)
+		}
+		t.block.groups[t.group] = wrap(value, width)
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+
+	for i, item := range blocks {
+		lines := item.raw
+		if item.raw == nil {
+			lines = slices.Concat(append([][]string{item.header}, item.groups...)...)
+		}
+
+		b.WriteString(strings.Join(lines, "\n"))
+		b.WriteString("\n")
+		b.WriteString(spaces[i])
+	}
+
+	result := b.String()
+	if !strings.HasSuffix(source, "\n") {
+		result = strings.TrimSuffix(result, "\n")
+	}
+
+	if eol != "\n" {
+		result = strings.ReplaceAll(result, "\n", eol)
+	}
+
+	if strings.HasPrefix(string(data), bom) {
+		result = bom + result
+	}
+
+	return []byte(result), nil
+}
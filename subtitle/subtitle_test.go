@@ -0,0 +1,79 @@
+package subtitle
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/internal/testfn"
+)
+
+func TestTranslate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		width    int
+		expected string
+	}{
+		{name: "empty"},
+		{
+			name:     "srt",
+			source:   "1\n00:00:01,000 --> 00:00:02,000\n<i>Hello</i>\nworld!\n\n2\n00:00:03,000 --> 00:00:04,000\n{\\an8}Second & last cue\n",
+			width:    10,
+			expected: "1\n00:00:01,000 --> 00:00:02,000\n<i>HELLO</i>\nWORLD!\n\n2\n00:00:03,000 --> 00:00:04,000\n{\\an8}SECOND &\nLAST CUE\n",
+		},
+		{
+			name:     "srt_windows",
+			source:   "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\nworld\r\n",
+			width:    42,
+			expected: "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nHELLO WORLD\r\n",
+		},
+		{
+			name:     "srt_dashes",
+			source:   "1\n00:00:01,000 --> 00:00:02,000\nIt is\n-5 degrees\n--\n- Really?\n",
+			width:    42,
+			expected: "1\n00:00:01,000 --> 00:00:02,000\nIT IS -5 DEGREES --\n- REALLY?\n",
+		},
+		{
+			name: "vtt",
+			source: "WEBVTT - title\n\nNOTE some --> comment\n\nSTYLE\n::cue { color: red }\n\n" +
+				"intro\n00:01.000 --> 00:02.000 align:start line:0\n<v Bob>Hi <c.loud>there</c>\n- Yes\n- No\n\n",
+			expected: "WEBVTT - title\n\nNOTE some --> comment\n\nSTYLE\n::cue { color: red }\n\n" +
+				"intro\n00:01.000 --> 00:02.000 align:start line:0\n<v Bob>HI <c.loud>THERE</c>\n- YES\n- NO\n\n",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Translate(context.Background(), []byte(tc.source), tc.width, testfn.UpperText)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if r := string(result); r != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, r)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	testCases := []struct {
+		text     string
+		width    int
+		expected []string
+	}{
+		{text: "", width: 5, expected: []string{""}},
+		{text: "one  two", expected: []string{"one two"}},
+		{text: "one two three", width: 7, expected: []string{"one two", "three"}},
+		{text: "<i>one</i> two", width: 7, expected: []string{"<i>one</i> two"}},
+		{text: "verylongword a", width: 4, expected: []string{"verylongword", "a"}},
+	}
+
+	for i, tc := range testCases {
+		if result := wrap(tc.text, tc.width); strings.Join(result, "|") != strings.Join(tc.expected, "|") {
+			t.Errorf("case %d: expected %q, got %q", i, tc.expected, result)
+		}
+	}
+}
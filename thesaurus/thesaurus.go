@@ -0,0 +1,133 @@
+// Package thesaurus renders synonyms of same-language dictionary lookups like "en-en".
+// Synonyms are grouped by parts of speech and meanings, reverse lookups show synonyms of every found synonym.
+package thesaurus
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+)
+
+// Group is synonyms of one meaning of the word.
+type Group struct {
+	Pos      string
+	Synonyms []string
+	Meanings []string
+}
+
+// Reverse is synonyms of a found synonym.
+type Reverse struct {
+	Word     string
+	Synonyms []string
+}
+
+// Result is a thesaurus article of the word.
+type Result struct {
+	Text    string
+	Ts      string
+	Groups  []Group
+	Reverse []Reverse
+}
+
+// New returns a thesaurus article built from the dictionary response,
+// every translation with its synonyms is a group of one meaning.
+func New(text string, r *dictionary.Response) *Result {
+	result := &Result{Text: text}
+
+	for _, def := range r.Def {
+		if result.Ts == "" {
+			result.Text, result.Ts = def.Text, def.Ts
+		}
+
+		for _, tr := range def.Tr {
+			group := Group{Pos: def.Pos, Synonyms: []string{tr.Text}}
+
+			for _, syn := range tr.Syn {
+				group.Synonyms = append(group.Synonyms, syn.Text)
+			}
+
+			for _, mean := range tr.Mean {
+				group.Meanings = append(group.Meanings, mean["text"])
+			}
+
+			result.Groups = append(result.Groups, group)
+		}
+	}
+
+	// groups of the same part of speech are shown together, articles order is kept
+	var order []string
+	for _, group := range result.Groups {
+		if !slices.Contains(order, group.Pos) {
+			order = append(order, group.Pos)
+		}
+	}
+
+	slices.SortStableFunc(result.Groups, func(a, b Group) int {
+		return slices.Index(order, a.Pos) - slices.Index(order, b.Pos)
+	})
+
+	return result
+}
+
+// Synonyms returns distinct synonyms of all groups except the word itself.
+func (r *Result) Synonyms() []string {
+	var synonyms []string
+
+	for _, group := range r.Groups {
+		for _, syn := range group.Synonyms {
+			if syn != "" && !strings.EqualFold(syn, r.Text) && !slices.Contains(synonyms, syn) {
+				synonyms = append(synonyms, syn)
+			}
+		}
+	}
+
+	return synonyms
+}
+
+// Exists is an implementation of Exists() method for Result.
+func (r *Result) Exists() bool {
+	return len(r.Groups) > 0
+}
+
+// String is an implementation of String() method for Result.
+func (r *Result) String() string {
+	if !r.Exists() {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(r.Text)
+
+	if r.Ts != "" {
+		fmt.Fprintf(&b, " [%s]", r.Ts)
+	}
+
+	number := 0
+	for i, group := range r.Groups {
+		if i == 0 || group.Pos != r.Groups[i-1].Pos {
+			number = 0
+			if group.Pos != "" {
+				b.WriteString("\n" + group.Pos)
+			}
+		}
+
+		number++
+		fmt.Fprintf(&b, "\n\t%d. %s", number, strings.Join(group.Synonyms, ", "))
+
+		if len(group.Meanings) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(group.Meanings, ", "))
+		}
+	}
+
+	if len(r.Reverse) > 0 {
+		b.WriteString("\nreverse:")
+	}
+
+	for _, reverse := range r.Reverse {
+		fmt.Fprintf(&b, "\n\t%s — %s", reverse.Word, strings.Join(reverse.Synonyms, ", "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package thesaurus
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/dictionary"
+)
+
+func testResponse() *dictionary.Response {
+	tr := func(text string, syn []string, mean ...string) dictionary.TrItem {
+		item := dictionary.TrItem{TextPosGen: dictionary.TextPosGen{Text: text}}
+		for _, s := range syn {
+			item.Syn = append(item.Syn, dictionary.TextPosGen{Text: s})
+		}
+		for _, m := range mean {
+			item.Mean = append(item.Mean, map[string]string{"text": m})
+		}
+		return item
+	}
+
+	return &dictionary.Response{Def: []dictionary.Article{
+		{Text: "quick", Pos: "adjective", Ts: "kwɪk", Tr: []dictionary.TrItem{tr("fast", []string{"rapid"}, "swift")}},
+		{Text: "quick", Pos: "noun", Tr: []dictionary.TrItem{tr("core", nil)}},
+		{Text: "quick", Pos: "adjective", Tr: []dictionary.TrItem{tr("clever", []string{"fast", "Quick"}, "smart", "bright")}},
+	}}
+}
+
+func TestNew(t *testing.T) {
+	r := New("Quick", testResponse())
+
+	if r.Text != "quick" || r.Ts != "kwɪk" {
+		t.Errorf("unexpected header %q [%q]", r.Text, r.Ts)
+	}
+
+	pos := make([]string, len(r.Groups))
+	for i, group := range r.Groups {
+		pos[i] = group.Pos
+	}
+
+	if expected := []string{"adjective", "adjective", "noun"}; !slices.Equal(pos, expected) {
+		t.Errorf("expected %q, got %q", expected, pos)
+	}
+
+	if expected, synonyms := []string{"fast", "rapid", "clever", "core"}, r.Synonyms(); !slices.Equal(synonyms, expected) {
+		t.Errorf("expected %q, got %q", expected, synonyms)
+	}
+
+	if empty := New("quick", &dictionary.Response{}); empty.Exists() || empty.String() != "" {
+		t.Errorf("empty result is not expected: %q", empty)
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	r := New("quick", testResponse())
+	r.Reverse = []Reverse{{Word: "fast", Synonyms: []string{"quick", "rapid"}}}
+
+	expected := "quick [kwɪk]\nadjective" +
+		"\n\t1. fast, rapid (swift)\n\t2. clever, fast, Quick (smart, bright)" +
+		"\nnoun\n\t1. core" +
+		"\nreverse:\n\tfast — quick, rapid"
+
+	if s := r.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
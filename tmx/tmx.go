@@ -0,0 +1,246 @@
+// Package tmx implements reading and writing of TMX 1.4 (Translation Memory eXchange) files.
+// Language codes are BCP-47 tags in canonical case like translation directions, "en_us" -> "en-US".
+package tmx
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/z0rr0/ytapigo/langtag"
+	"github.com/z0rr0/ytapigo/memory"
+)
+
+const (
+	// Version is a supported TMX version.
+	Version = "1.4"
+
+	// dateLayout is TMX date format in UTC.
+	dateLayout = "20060102T150405Z"
+
+	// allLanguages is a value of srclang attribute when any language can be a source one.
+	allLanguages = "*all*"
+)
+
+// Header is TMX header.
+type Header struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTMF                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+// variant is a translation unit variant.
+type variant struct {
+	Lang    string   `xml:"lang,attr"` // TMX 1.1 attribute
+	XMLLang string   `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Seg     mixedSeg `xml:"seg"`
+}
+
+// language returns a language of the variant.
+func (v *variant) language() string {
+	return language(cmp.Or(v.XMLLang, v.Lang))
+}
+
+// unit is a translation unit.
+type unit struct {
+	SrcLang      string    `xml:"srclang,attr"`
+	CreationDate string    `xml:"creationdate,attr"`
+	ChangeDate   string    `xml:"changedate,attr"`
+	Variants     []variant `xml:"tuv"`
+}
+
+// document is a TMX document for reading.
+type document struct {
+	XMLName xml.Name `xml:"tmx"`
+	Version string   `xml:"version,attr"`
+	Header  Header   `xml:"header"`
+	Units   []unit   `xml:"body>tu"`
+}
+
+// mixedSeg is a segment text, native codes of inline elements (bpt, ept, it, ph, ut) are kept as a text,
+// sub-flows of them are skipped.
+type mixedSeg string
+
+// UnmarshalXML is an implementation of xml.Unmarshaler for segment text.
+func (s *mixedSeg) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var (
+		b     strings.Builder
+		skip  int
+		depth = 1
+	)
+
+	for depth > 0 {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if skip > 0 || t.Name.Local == "sub" {
+				skip++
+			}
+		case xml.EndElement:
+			depth--
+			if skip > 0 {
+				skip--
+			}
+		case xml.CharData:
+			if skip == 0 {
+				b.Write(t)
+			}
+		}
+	}
+
+	*s = mixedSeg(b.String())
+	return nil
+}
+
+// language returns the code as a language tag in canonical case,
+// so imported entries match memory lookups of translation directions.
+func language(code string) string {
+	if code == allLanguages {
+		return code
+	}
+
+	return langtag.Canonical(strings.ReplaceAll(code, "_", "-"))
+}
+
+// parseDate parses TMX date, zero time is returned for empty or invalid values.
+func parseDate(value string) time.Time {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Read reads translation memory entries from TMX data.
+// A unit with several target variants produces several entries,
+// units with "*all*" source language produce entries for every pair of variants.
+func Read(r io.Reader) ([]*memory.Entry, error) {
+	var doc document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse tmx: %w", err)
+	}
+
+	var entries []*memory.Entry
+	for i, u := range doc.Units {
+		srcLang := language(cmp.Or(u.SrcLang, doc.Header.SrcLang, allLanguages))
+		created := parseDate(u.CreationDate)
+		updated := cmp.Or(u.ChangeDate, u.CreationDate)
+
+		for _, source := range u.Variants {
+			from := source.language()
+			if from == "" {
+				return nil, fmt.Errorf("tmx unit %d: variant language is empty", i+1)
+			}
+
+			if srcLang != allLanguages && from != srcLang {
+				continue
+			}
+
+			for _, target := range u.Variants {
+				to := target.language()
+				if to == from || strings.TrimSpace(string(source.Seg)) == "" || strings.TrimSpace(string(target.Seg)) == "" {
+					continue
+				}
+
+				entries = append(entries, &memory.Entry{
+					Source:  string(source.Seg),
+					Target:  string(target.Seg),
+					From:    from,
+					To:      to,
+					Created: created,
+					Updated: parseDate(updated),
+				})
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// outVariant is a translation unit variant for writing.
+type outVariant struct {
+	Lang string `xml:"xml:lang,attr"`
+	Seg  string `xml:"seg"`
+}
+
+// outUnit is a translation unit for writing.
+type outUnit struct {
+	SrcLang      string       `xml:"srclang,attr"`
+	CreationDate string       `xml:"creationdate,attr,omitempty"`
+	ChangeDate   string       `xml:"changedate,attr,omitempty"`
+	Variants     []outVariant `xml:"tuv"`
+}
+
+// outDocument is a TMX document for writing.
+type outDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  Header    `xml:"header"`
+	Units   []outUnit `xml:"body>tu"`
+}
+
+// formatDate returns TMX date or empty string for zero time.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(dateLayout)
+}
+
+// Write writes translation memory entries as TMX document, header fields are filled if they are empty.
+func Write(w io.Writer, entries []*memory.Entry, header Header) error {
+	doc := outDocument{Version: Version, Header: header, Units: make([]outUnit, 0, len(entries))}
+	languages := make(map[string]struct{})
+
+	for _, e := range entries {
+		languages[e.From] = struct{}{}
+		doc.Units = append(doc.Units, outUnit{
+			SrcLang:      e.From,
+			CreationDate: formatDate(e.Created),
+			ChangeDate:   formatDate(e.Updated),
+			Variants:     []outVariant{{Lang: e.From, Seg: e.Source}, {Lang: e.To, Seg: e.Target}},
+		})
+	}
+
+	h := &doc.Header
+	h.SegType = cmp.Or(h.SegType, "sentence")
+	h.OTMF = cmp.Or(h.OTMF, h.CreationTool)
+	h.AdminLang = cmp.Or(h.AdminLang, "en")
+	h.DataType = cmp.Or(h.DataType, "plaintext")
+
+	if h.SrcLang == "" {
+		h.SrcLang = allLanguages
+		if len(languages) == 1 {
+			h.SrcLang = entries[0].From
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write tmx: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("write tmx: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("write tmx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package tmx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/ytapigo/memory"
+)
+
+func TestRead(t *testing.T) {
+	const data = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="agency" creationtoolversion="1" segtype="sentence" o-tmf="x" adminlang="en-US" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu creationdate="20240102T030405Z">
+      <tuv xml:lang="en-US"><seg>Hello, <ph x="1">{name}</ph> <bpt i="1">&lt;b&gt;<sub>x</sub></bpt><hi>world</hi><ept i="1">&lt;/b&gt;</ept>!</seg></tuv>
+      <tuv xml:lang="ru-RU"><seg>Привет, <ph x="1">{name}</ph> мир!</seg></tuv>
+      <tuv xml:lang="de"><seg>Hallo, Welt!</seg></tuv>
+    </tu>
+    <tu srclang="*all*" changedate="20240203T000000Z">
+      <tuv lang="fr"><seg>Oui</seg></tuv>
+      <tuv lang="es"><seg>Sí</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="en"><seg></seg></tuv>
+      <tuv xml:lang="ru"><seg>Пусто</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+
+	entries, err := Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"en-US-ru-RU Hello, {name} <b>world</b>! -> Привет, {name} мир!",
+		"en-US-de Hello, {name} <b>world</b>! -> Hallo, Welt!",
+		"fr-es Oui -> Sí",
+		"es-fr Sí -> Oui",
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+
+	for i, e := range entries {
+		if s := e.From + "-" + e.To + " " + e.Source + " -> " + e.Target; s != expected[i] {
+			t.Errorf("entry %d: expected %q, got %q", i, expected[i], s)
+		}
+	}
+
+	if created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !entries[0].Created.Equal(created) || !entries[0].Updated.Equal(created) {
+		t.Errorf("unexpected dates %v and %v", entries[0].Created, entries[0].Updated)
+	}
+
+	if _, err = Read(strings.NewReader("<tmx><body>")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []*memory.Entry{
+		{Source: "a < b", Target: "а < б", From: "en", To: "ru", Created: created, Updated: created},
+		{Source: "yes", Target: "ja", From: "en", To: "de"},
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, entries, Header{CreationTool: "ytapigo", CreationToolVersion: "1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		`<tmx version="1.4">`,
+		`srclang="en" datatype="plaintext"></header>`,
+		`<tu srclang="en" creationdate="20240102T030405Z" changedate="20240102T030405Z">`,
+		`<tuv xml:lang="ru">`,
+		`<seg>а &lt; б</seg>`,
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("%q not found in %s", s, b.String())
+		}
+	}
+
+	result, err := Read(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 2 || *result[0] != *entries[0] || result[1].Target != "ja" {
+		t.Errorf("unexpected entries %v", result)
+	}
+}
@@ -0,0 +1,329 @@
+package translation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/z0rr0/ytapigo/config"
+)
+
+// MaxLength is a max total length of texts (in characters) for one translation request.
+// Documentation https://yandex.cloud/en/docs/translate/concepts/limits
+const MaxLength = 10000
+
+// sentenceEnds are runes which can finish a sentence.
+const sentenceEnds = ".!?…。！？"
+
+// Segment is a text part for translation with the whitespace following it.
+type Segment struct {
+	Text  string
+	Space string
+}
+
+// length returns a number of characters in the text.
+func length(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// splitSpaces splits text by whitespace runs, a run is a segment separator only if isBoundary returns true,
+// trailing whitespace is always a separator. The text must not have leading whitespace.
+func splitSpaces(text string, isBoundary func(before, space string) bool) []Segment {
+	var (
+		segments []Segment
+		start, i int
+	)
+
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		j := i + size
+		for j < len(text) {
+			r, size = utf8.DecodeRuneInString(text[j:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			j += size
+		}
+
+		if j == len(text) || isBoundary(text[start:i], text[i:j]) {
+			segments = append(segments, Segment{Text: text[start:i], Space: text[i:j]})
+			start = j
+		}
+		i = j
+	}
+
+	if start < len(text) {
+		segments = append(segments, Segment{Text: text[start:]})
+	}
+
+	return segments
+}
+
+// isParagraphBreak returns true if space contains an empty line.
+func isParagraphBreak(_, space string) bool {
+	return strings.Count(space, "\n") > 1
+}
+
+// isSentenceBreak returns true if space follows the end of a sentence or it is a line break.
+func isSentenceBreak(before, space string) bool {
+	if strings.Contains(space, "\n") {
+		return true
+	}
+
+	before = strings.TrimRight(before, `"'»”’)]`)
+	r, _ := utf8.DecodeLastRuneInString(before)
+
+	return strings.ContainsRune(sentenceEnds, r)
+}
+
+// isWordBreak returns true for any space.
+func isWordBreak(_, _ string) bool {
+	return true
+}
+
+// pack joins neighboring segments while their total length is not greater than limit.
+// Segments which are longer than limit are divided by split function.
+func pack(segments []Segment, limit int, split func(string, int) []Segment) []Segment {
+	var (
+		result  = make([]Segment, 0, len(segments))
+		current = -1 // index of the last segment which can be extended
+	)
+
+	for _, s := range segments {
+		n := length(s.Text)
+
+		if n > limit {
+			parts := split(s.Text, limit)
+			parts[len(parts)-1].Space = s.Space
+			result = append(result, parts...)
+			current = -1
+			continue
+		}
+
+		if current >= 0 {
+			last := &result[current]
+			if length(last.Text)+length(last.Space)+n <= limit {
+				last.Text += last.Space + s.Text
+				last.Space = s.Space
+				continue
+			}
+		}
+
+		result = append(result, s)
+		current = len(result) - 1
+	}
+
+	return result
+}
+
+// splitParagraph splits a long paragraph by sentences.
+func splitParagraph(text string, limit int) []Segment {
+	return pack(splitSpaces(text, isSentenceBreak), limit, splitSentence)
+}
+
+// splitSentence splits a long sentence by words.
+func splitSentence(text string, limit int) []Segment {
+	return pack(splitSpaces(text, isWordBreak), limit, splitWord)
+}
+
+// splitWord splits a long word to parts with limit characters.
+func splitWord(text string, limit int) []Segment {
+	runes := []rune(text)
+	segments := make([]Segment, 0, len(runes)/limit+1)
+
+	for i := 0; i < len(runes); i += limit {
+		segments = append(segments, Segment{Text: string(runes[i:min(i+limit, len(runes))])})
+	}
+
+	return segments
+}
+
+// Split splits text to segments which are not longer than limit characters.
+// Paragraphs are always separated, long paragraphs are split by sentences,
+// and long sentences - by words. It returns leading whitespace of the text and its segments,
+// so the source text is prefix + Text and Space of every segment.
+func Split(text string, limit int) (string, []Segment) {
+	body := strings.TrimLeftFunc(text, unicode.IsSpace)
+	prefix := text[:len(text)-len(body)]
+
+	if body == "" {
+		return prefix, nil
+	}
+
+	var segments []Segment
+	for _, p := range splitSpaces(body, isParagraphBreak) {
+		if length(p.Text) <= limit {
+			segments = append(segments, p)
+			continue
+		}
+
+		parts := splitParagraph(p.Text, limit)
+		parts[len(parts)-1].Space = p.Space
+		segments = append(segments, parts...)
+	}
+
+	return prefix, segments
+}
+
+// Join builds a text from prefix and segments replacing their texts by translations.
+func Join(prefix string, segments []Segment, translations []string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+
+	for i, s := range segments {
+		b.WriteString(translations[i])
+		b.WriteString(s.Space)
+	}
+
+	return b.String()
+}
+
+// batchItem is a reference to a segment of a text.
+type batchItem struct {
+	text    int
+	segment int
+}
+
+// batchGroups combines segments to groups with total length not greater than limit.
+func batchGroups(segments [][]Segment, limit int) [][]batchItem {
+	var (
+		groups [][]batchItem
+		group  []batchItem
+		size   int
+	)
+
+	for i := range segments {
+		for j, s := range segments[i] {
+			n := length(s.Text)
+
+			if len(group) > 0 && size+n > limit {
+				groups = append(groups, group)
+				group, size = nil, 0
+			}
+
+			group = append(group, batchItem{text: i, segment: j})
+			size += n
+		}
+	}
+
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// RequestFunc is a function which translates texts of the request by one API call.
+type RequestFunc func(ctx context.Context, r *Request) (*Response, error)
+
+// Batch translates texts of any length. Every text is split by Split function,
+// its segments are grouped to requests up to MaxLength characters,
+// and not more than parallel requests are done concurrently.
+// The result contains translations in the same order as request texts.
+func Batch(ctx context.Context, client *http.Client, cfg *config.Config, r *Request, parallel int) (*Response, error) {
+	return BatchBy(ctx, r, parallel, func(ctx context.Context, request *Request) (*Response, error) {
+		return Translate(ctx, client, cfg, request)
+	})
+}
+
+// BatchBy translates texts of any length like Batch, but every request is done by fn function.
+func BatchBy(ctx context.Context, r *Request, parallel int, fn RequestFunc) (*Response, error) {
+	var (
+		n            = len(r.Texts)
+		prefixes     = make([]string, n)
+		segments     = make([][]Segment, n)
+		translations = make([][]string, n)
+	)
+
+	for i, text := range r.Texts {
+		prefixes[i], segments[i] = Split(text, MaxLength)
+		translations[i] = make([]string, len(segments[i]))
+	}
+
+	groups := batchGroups(segments, MaxLength)
+	detected := make([]string, n)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		once      sync.Once
+		batchErr  error
+		semaphore = make(chan struct{}, max(parallel, 1))
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			batchErr = err
+			cancel()
+		})
+	}
+
+	for _, group := range groups {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if err := ctx.Err(); err != nil {
+				fail(err) // it does nothing if another request has already failed
+				return
+			}
+
+			texts := make([]string, len(group))
+			for j, item := range group {
+				texts[j] = segments[item.text][item.segment].Text
+			}
+
+			request := &Request{
+				FolderID:           r.FolderID,
+				Texts:              texts,
+				TargetLanguageCode: r.TargetLanguageCode,
+				SourceLanguageCode: r.SourceLanguageCode,
+				Format:             r.Format,
+			}
+
+			response, err := fn(ctx, request)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			if m := len(response.Translations); m != len(texts) {
+				fail(fmt.Errorf("unexpected translations count %d, expected %d", m, len(texts)))
+				return
+			}
+
+			for j, item := range group {
+				translations[item.text][item.segment] = response.Translations[j].Text
+				if item.segment == 0 {
+					detected[item.text] = response.Translations[j].DetectedLanguageCode
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if batchErr != nil {
+		return nil, batchErr
+	}
+
+	response := &Response{Translations: make([]ResponseItem, n)}
+	for i := range n {
+		response.Translations[i] = ResponseItem{
+			Text:                 Join(prefixes[i], segments[i], translations[i]),
+			DetectedLanguageCode: detected[i],
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,147 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/cloud"
+	"github.com/z0rr0/ytapigo/config"
+)
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		limit    int
+		prefix   string
+		expected []Segment
+	}{
+		{name: "empty", limit: 10},
+		{name: "spaces", text: " \n ", limit: 10, prefix: " \n "},
+		{name: "short", text: "Hello world", limit: 20, expected: []Segment{{Text: "Hello world"}}},
+		{
+			name:     "paragraphs",
+			text:     "\n First line.\nSecond line.\n\n  Next paragraph. \n",
+			limit:    100,
+			prefix:   "\n ",
+			expected: []Segment{{"First line.\nSecond line.", "\n\n  "}, {"Next paragraph.", " \n"}},
+		},
+		{
+			name:     "sentences",
+			text:     "One two. Three four! Five six?",
+			limit:    20,
+			expected: []Segment{{"One two. Three four!", " "}, {"Five six?", ""}},
+		},
+		{
+			name:     "words",
+			text:     "one two three four",
+			limit:    9,
+			expected: []Segment{{"one two", " "}, {"three", " "}, {"four", ""}},
+		},
+		{
+			name:     "long_word",
+			text:     "абвгдеж з",
+			limit:    3,
+			expected: []Segment{{"абв", ""}, {"где", ""}, {"ж", " "}, {"з", ""}},
+		},
+		{
+			name:     "quotes",
+			text:     `He said "Stop." Then left.`,
+			limit:    16,
+			expected: []Segment{{`He said "Stop."`, " "}, {"Then left.", ""}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			prefix, segments := Split(tc.text, tc.limit)
+
+			if prefix != tc.prefix {
+				t.Errorf("expected prefix %q, got %q", tc.prefix, prefix)
+			}
+
+			if len(segments) != len(tc.expected) {
+				t.Fatalf("expected %d segments, got %d: %#v", len(tc.expected), len(segments), segments)
+			}
+
+			texts := make([]string, len(segments))
+			for j, s := range segments {
+				if s != tc.expected[j] {
+					t.Errorf("segment %d: expected %#v, got %#v", j, tc.expected[j], s)
+				}
+
+				if n := length(s.Text); n > tc.limit {
+					t.Errorf("segment %d: too long %d", j, n)
+				}
+				texts[j] = s.Text
+			}
+
+			if text := Join(prefix, segments, texts); text != tc.text {
+				t.Errorf("failed join: %q", text)
+			}
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var requests atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		req := &Request{}
+
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Error(err)
+			return
+		}
+
+		response := &Response{Translations: make([]ResponseItem, len(req.Texts))}
+		for i, text := range req.Texts {
+			if n := length(text); n > MaxLength {
+				t.Errorf("too long text %d", n)
+			}
+			response.Translations[i] = ResponseItem{Text: strings.ToUpper(text), DetectedLanguageCode: "en"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer s.Close()
+
+	cfg := &config.Config{
+		Translation: cloud.Account{FolderID: "folder_id", IAMToken: "token"},
+		URL:         map[string]string{URL: s.URL},
+		Logger:      logger,
+	}
+
+	paragraph := strings.Repeat("Some sentence. ", MaxLength/10)
+	texts := []string{"short text", "\n" + paragraph + "\n\n" + paragraph + "\n"}
+	req := &Request{FolderID: "folder_id", Texts: texts, SourceLanguageCode: "en", TargetLanguageCode: "ru"}
+
+	resp, err := Batch(context.Background(), s.Client(), cfg, req, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(resp.Translations); n != len(texts) {
+		t.Fatalf("unexpected translations count %d", n)
+	}
+
+	for i, text := range texts {
+		if expected := strings.ToUpper(text); resp.Translations[i].Text != expected {
+			t.Errorf("failed translation %d", i)
+		}
+	}
+
+	if n := requests.Load(); n != 4 {
+		t.Errorf("unexpected requests count %d", n)
+	}
+}
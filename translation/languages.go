@@ -102,10 +102,14 @@ func LoadLanguages(ctx context.Context, client *http.Client, cfg *config.Config)
 		return nil, fmt.Errorf("failed to decode translation languages response: %w", err)
 	}
 
-	languages.Codes = make(map[string]struct{}, languages.Len())
-	for _, v := range languages.Languages {
+	return NewLanguages(languages.Languages), nil
+}
+
+// NewLanguages creates languages list with codes index.
+func NewLanguages(items []Language) *Languages {
+	languages := &Languages{Languages: items, Codes: make(map[string]struct{}, len(items))}
+	for _, v := range items {
 		languages.Codes[strings.ToLower(v.Code)] = struct{}{}
 	}
-
-	return &languages, nil
+	return languages
 }
@@ -17,6 +17,17 @@ import (
 // Documentation https://cloud.yandex.com/en/docs/translate/api-ref/Translation/translate
 const URL = "https://translate.api.cloud.yandex.net/translate/v2/translate"
 
+// Formats of translated texts.
+const (
+	// FormatPlain is a plain text format, it is used by default.
+	FormatPlain = "PLAIN_TEXT"
+	// FormatHTML is HTML format, tags and their attributes are not translated.
+	FormatHTML = "HTML"
+)
+
+// BatchFunc is a function which translates a batch of texts keeping their order.
+type BatchFunc func(ctx context.Context, texts []string) ([]string, error)
+
 // ResponseItem is an item for translation request.
 type ResponseItem struct {
 	Text                 string `json:"text"`
@@ -51,6 +62,7 @@ type Request struct {
 	Texts              []string `json:"texts"`
 	TargetLanguageCode string   `json:"targetLanguageCode"`
 	SourceLanguageCode string   `json:"sourceLanguageCode"`
+	Format             string   `json:"format,omitempty"`
 }
 
 // Translate returns translated text.
@@ -0,0 +1,537 @@
+// Package xliff implements translation of XLIFF 1.2 and 2.0 files.
+// Source segments without targets are translated, new targets get review states
+// ("needs-review-translation" for XLIFF 1.2 and "translated" for XLIFF 2.0).
+// Inline elements, notes and formatting of the file are kept as is.
+package xliff
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/z0rr0/ytapigo/markup"
+	"github.com/z0rr0/ytapigo/translation"
+)
+
+const (
+	// StateReview is a state of translated XLIFF 1.2 targets.
+	StateReview = "needs-review-translation"
+	// StateTranslated is a state of translated XLIFF 2.0 segments.
+	StateTranslated = "translated"
+)
+
+var (
+	codeRegexp      = regexp.MustCompile("\x00(\\d+)\x00")
+	attrRegexp      = regexp.MustCompile(`\s([^\s=/>]+)\s*=\s*("[^"]*"|'[^']*')`)
+	xmlTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// codeElements are XLIFF 1.2 inline elements which contain native codes, they are never translated.
+var codeElements = []string{"bpt", "ept", "it", "ph", "ut"}
+
+// element is a raw XML element position.
+type element struct {
+	start    int // start of the start tag
+	end      int // end of the start tag
+	inner    int // end of the element content
+	close    int // end of the element
+	tag      string
+	selfEnds bool
+}
+
+// content returns a raw content of the element.
+func (e *element) content(data string) string {
+	if e.selfEnds {
+		return ""
+	}
+	return data[e.end:e.inner]
+}
+
+// edit is a replacement of data part.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// segment is a translatable source and its existing target.
+type segment struct {
+	source *element
+	target *element
+	state  *element // element with state attribute
+}
+
+// parser is a state of XLIFF parsing.
+type parser struct {
+	data     string
+	version  int // major version 1 or 2
+	root     *element
+	files    []*element
+	segments []*segment
+}
+
+// setAttr returns a start tag with set attribute value.
+func setAttr(tag, name, value string) string {
+	quoted := strconv.Quote(xmlTextReplacer.Replace(value))
+
+	for _, loc := range attrRegexp.FindAllStringSubmatchIndex(tag, -1) {
+		if tag[loc[2]:loc[3]] == name {
+			return tag[:loc[4]] + quoted + tag[loc[5]:]
+		}
+	}
+
+	end := len(tag) - 1
+	if strings.HasSuffix(tag, "/>") {
+		end--
+	}
+
+	return strings.TrimRight(tag[:end], " \t\r\n") + " " + name + "=" + quoted + tag[end:]
+}
+
+// hasAttr returns true if the start element has not empty attribute.
+func hasAttr(t xml.StartElement, name string) bool {
+	return attr(t, name) != ""
+}
+
+// attr returns a value of the start element attribute.
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// indentBefore returns whitespace between the line beginning and position.
+func indentBefore(data string, position int) string {
+	start := strings.LastIndexByte(data[:position], '\n') + 1
+	if indent := data[start:position]; strings.TrimSpace(indent) == "" {
+		return indent
+	}
+	return ""
+}
+
+// parse finds translatable segments of XLIFF data.
+func parse(data string) (*parser, error) {
+	var (
+		p       = &parser{data: data}
+		dec     = xml.NewDecoder(strings.NewReader(data))
+		stack   []string
+		opened  []*element
+		current *segment
+		skip    int // depth of not translatable unit
+		prev    int
+	)
+
+	for {
+		token, err := dec.RawToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		start, end := prev, int(dec.InputOffset())
+		prev = end
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var (
+				name   = t.Name.Local
+				parent string
+				e      = &element{start: start, end: end, tag: data[start:end]}
+			)
+
+			if n := len(stack); n > 0 {
+				parent = stack[n-1]
+			}
+
+			e.selfEnds = strings.HasSuffix(e.tag, "/>")
+			stack = append(stack, name)
+			opened = append(opened, e)
+
+			switch {
+			case name == "xliff" && parent == "":
+				p.root = e
+				version := attr(t, "version")
+				if strings.HasPrefix(version, "2") {
+					p.version = 2
+				} else {
+					p.version = 1
+				}
+			case skip > 0:
+				skip++
+			case name == "file" && parent == "xliff":
+				p.files = append(p.files, e)
+			case (name == "trans-unit" || name == "unit") && attr(t, "translate") == "no":
+				skip = 1
+			case name == "trans-unit" && p.version == 1:
+				current = &segment{}
+				p.segments = append(p.segments, current)
+			case name == "segment" && parent == "unit" && p.version == 2:
+				current = &segment{state: e}
+				p.segments = append(p.segments, current)
+			case current != nil && name == "source" && (parent == "trans-unit" || parent == "segment"):
+				current.source = e
+			case current != nil && name == "target" && (parent == "trans-unit" || parent == "segment"):
+				current.target = e
+			}
+		case xml.EndElement:
+			n := len(stack)
+			if n == 0 {
+				return nil, fmt.Errorf("unexpected closing tag %q", t.Name.Local)
+			}
+
+			e := opened[n-1]
+			stack, opened = stack[:n-1], opened[:n-1]
+			e.inner, e.close = start, end
+
+			if e.selfEnds {
+				e.inner = e.end
+			}
+
+			if skip > 0 {
+				skip--
+			}
+		}
+	}
+
+	if p.root == nil {
+		return nil, fmt.Errorf("xliff element not found")
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("not closed element %q", stack[len(stack)-1])
+	}
+
+	return p, nil
+}
+
+// item is a raw inline content part.
+type item struct {
+	raw   string
+	text  string // decoded text for character data
+	code  bool   // protected element
+	open  bool   // opening tag of paired element
+	close bool   // closing tag of paired element
+	pair  int    // index of matched closing tag
+}
+
+// inline splits raw content to texts and inline elements.
+func inline(content string) ([]*item, error) {
+	var (
+		items  []*item
+		opened []int
+		dec    = xml.NewDecoder(strings.NewReader(content))
+		prev   int
+		depth  int // depth of code element
+	)
+	dec.Strict = false
+
+	for {
+		token, err := dec.RawToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		start, end := prev, int(dec.InputOffset())
+		prev = end
+		raw := content[start:end]
+
+		if depth > 0 {
+			// content of code element is a part of it
+			items[len(items)-1].raw += raw
+			switch token.(type) {
+			case xml.StartElement:
+				if !strings.HasSuffix(raw, "/>") {
+					depth++
+				}
+			case xml.EndElement:
+				if raw != "" {
+					depth--
+				}
+			}
+			continue
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			items = append(items, &item{raw: raw, text: string(t)})
+		case xml.StartElement:
+			selfEnds := strings.HasSuffix(raw, "/>")
+			items = append(items, &item{raw: raw, code: true})
+
+			if !selfEnds {
+				if slices.Contains(codeElements, t.Name.Local) {
+					depth = 1
+					continue
+				}
+
+				items[len(items)-1].code, items[len(items)-1].open = false, true
+				opened = append(opened, len(items)-1)
+			}
+		case xml.EndElement:
+			if raw == "" {
+				continue // end of self-closing element
+			}
+
+			n := len(opened)
+			if n == 0 {
+				return nil, fmt.Errorf("unexpected closing tag %q", raw)
+			}
+
+			items[opened[n-1]].pair = len(items)
+			opened = opened[:n-1]
+			items = append(items, &item{raw: raw, close: true})
+		default:
+			items = append(items, &item{raw: raw, code: true}) // comments and processing instructions
+		}
+	}
+
+	if len(opened) > 0 {
+		return nil, fmt.Errorf("not closed inline element %q", items[opened[0]].raw)
+	}
+
+	return items, nil
+}
+
+// text is a translatable source content.
+type text struct {
+	segment *segment
+	tags    *markup.Text
+	codes   []string
+}
+
+// code returns a placeholder of raw XML code.
+func (t *text) code(raw string) string {
+	t.codes = append(t.codes, raw)
+	return codePlaceholder(len(t.codes) - 1)
+}
+
+// codePlaceholder returns a placeholder of raw XML code with index i.
+func codePlaceholder(i int) string {
+	return fmt.Sprintf("\x00%d\x00", i)
+}
+
+// mask converts items to HTML text with protected inline elements.
+func (t *text) mask(items []*item) string {
+	var (
+		b       strings.Builder
+		closing = make(map[int]string)
+	)
+
+	for i, it := range items {
+		switch {
+		case it.code:
+			b.WriteString(t.tags.Token(t.code(it.raw)))
+		case it.open:
+			open, closed := t.tags.Pair(t.code(it.raw), t.code(items[it.pair].raw))
+			b.WriteString(open)
+			closing[it.pair] = closed
+		case it.close:
+			b.WriteString(closing[i])
+		default:
+			b.WriteString(markup.Escape(it.text))
+		}
+	}
+
+	return b.String()
+}
+
+// restore converts translated HTML text to XML content.
+func (t *text) restore(s string) (string, error) {
+	value, err := t.tags.Restore(s)
+	if err != nil {
+		// error message contains quoted placeholders instead of raw codes
+		msg := err.Error()
+		for i, raw := range t.codes {
+			msg = strings.ReplaceAll(msg, strings.Trim(strconv.Quote(codePlaceholder(i)), `"`), raw)
+		}
+		return "", errors.New(msg)
+	}
+
+	value = xmlTextReplacer.Replace(value)
+	return codeRegexp.ReplaceAllStringFunc(value, func(m string) string {
+		i, _ := strconv.Atoi(m[1 : len(m)-1])
+		return t.codes[i]
+	}), nil
+}
+
+// targetEdits returns edits which set translated content of the segment.
+func (p *parser) targetEdits(s *segment, content string) []edit {
+	var edits []edit
+
+	if p.version == 2 && s.state != nil {
+		edits = append(edits, edit{start: s.state.start, end: s.state.end, text: setAttr(s.state.tag, "state", StateTranslated)})
+	}
+
+	state := func(tag string) string {
+		if p.version == 1 {
+			return setAttr(tag, "state", StateReview)
+		}
+		return tag
+	}
+
+	if t := s.target; t != nil {
+		tag := state(t.tag)
+		if t.selfEnds {
+			tag = strings.TrimRight(strings.TrimSuffix(tag, "/>"), " \t\r\n") + ">"
+		}
+		return append(edits, edit{start: t.start, end: t.close, text: tag + content + "</" + tagName(t.tag, "target") + ">"})
+	}
+
+	// new target has the same namespace prefix as the source
+	name := strings.TrimSuffix(tagName(s.source.tag, "source"), "source") + "target"
+	text := "\n" + indentBefore(p.data, s.source.start) + state("<"+name+">") + content + "</" + name + ">"
+
+	return append(edits, edit{start: s.source.close, end: s.source.close, text: text})
+}
+
+// tagName returns a qualified name of the raw start tag or defaultName if it can not be parsed.
+func tagName(tag, defaultName string) string {
+	t, err := startElement(tag)
+	if err != nil {
+		return defaultName
+	}
+
+	if t.Name.Space != "" {
+		return t.Name.Space + ":" + t.Name.Local
+	}
+	return t.Name.Local
+}
+
+// languageEdits returns edits which set target language if it is not set.
+func (p *parser) languageEdits(target string) []edit {
+	var (
+		edits    []edit
+		elements = p.files
+		name     = "target-language"
+	)
+
+	if p.version == 2 {
+		elements, name = []*element{p.root}, "trgLang"
+	}
+
+	for _, e := range elements {
+		start, err := startElement(e.tag)
+		if err != nil || hasAttr(start, name) {
+			continue
+		}
+		edits = append(edits, edit{start: e.start, end: e.end, text: setAttr(e.tag, name, target)})
+	}
+
+	return edits
+}
+
+// startElement parses a raw start tag.
+func startElement(tag string) (xml.StartElement, error) {
+	token, err := xml.NewDecoder(strings.NewReader(tag)).RawToken()
+	if err != nil {
+		return xml.StartElement{}, err
+	}
+
+	t, ok := token.(xml.StartElement)
+	if !ok {
+		return xml.StartElement{}, fmt.Errorf("not a start tag %q", tag)
+	}
+
+	return t, nil
+}
+
+// apply returns data with applied edits.
+func apply(data string, edits []edit) string {
+	slices.SortStableFunc(edits, func(a, b edit) int {
+		return a.start - b.start
+	})
+
+	var (
+		b     strings.Builder
+		start int
+	)
+
+	for _, e := range edits {
+		b.WriteString(data[start:e.start])
+		b.WriteString(e.text)
+		start = e.end
+	}
+
+	b.WriteString(data[start:])
+	return b.String()
+}
+
+// Translate translates XLIFF data by HTML translation function fn,
+// target is a target language code which is set to the file if it's not defined there.
+func Translate(ctx context.Context, data []byte, target string, fn translation.BatchFunc) ([]byte, error) {
+	source := string(data)
+
+	p, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse xliff: %w", err)
+	}
+
+	var (
+		texts []*text
+		items []string
+	)
+
+	for _, s := range p.segments {
+		if s.source == nil || s.source.selfEnds {
+			continue
+		}
+
+		if s.target != nil && strings.TrimSpace(s.target.content(source)) != "" {
+			continue // already translated
+		}
+
+		content := s.source.content(source)
+		parts, err := inline(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse source %q: %w", content, err)
+		}
+
+		isText := func(it *item) bool {
+			return !it.code && !it.open && !it.close && strings.TrimSpace(it.text) != ""
+		}
+
+		if !slices.ContainsFunc(parts, isText) {
+			continue // only inline codes
+		}
+
+		t := &text{segment: s, tags: &markup.Text{}}
+		texts = append(texts, t)
+		items = append(items, t.mask(parts))
+	}
+
+	var translations []string
+	if len(items) > 0 {
+		if translations, err = fn(ctx, items); err != nil {
+			return nil, err
+		}
+
+		if len(translations) != len(items) {
+			return nil, fmt.Errorf("unexpected translations count %d, expected %d", len(translations), len(items))
+		}
+	}
+
+	edits := p.languageEdits(target)
+	for i, t := range texts {
+		content, err := t.restore(translations[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed source %q: %w", t.segment.source.content(source), err)
+		}
+		edits = append(edits, p.targetEdits(t.segment, content)...)
+	}
+
+	return []byte(apply(source, edits)), nil
+}
@@ -0,0 +1,167 @@
+package xliff
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/z0rr0/ytapigo/internal/testfn"
+)
+
+func TestTranslate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+		err      string
+	}{
+		{
+			name: "xliff12",
+			source: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" datatype="plaintext" original="app">
+    <body>
+      <trans-unit id="1">
+        <source>Hello, <g id="1">big</g> world &amp; <ph id="2">&lt;br/&gt;</ph><x id="3"/></source>
+        <note>Greeting</note>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>Done</source>
+        <target state="translated">Готово</target>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>Empty</source>
+        <target/>
+      </trans-unit>
+      <trans-unit id="4" translate="no">
+        <source>Brand</source>
+      </trans-unit>
+      <trans-unit id="5">
+        <source><x id="1"/></source>
+        <alt-trans><source>other</source><target>другой</target></alt-trans>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" datatype="plaintext" original="app" target-language="ru">
+    <body>
+      <trans-unit id="1">
+        <source>Hello, <g id="1">big</g> world &amp; <ph id="2">&lt;br/&gt;</ph><x id="3"/></source>
+        <target state="needs-review-translation">HELLO, <g id="1">BIG</g> WORLD &amp; <ph id="2">&lt;br/&gt;</ph><x id="3"/></target>
+        <note>Greeting</note>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>Done</source>
+        <target state="translated">Готово</target>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>Empty</source>
+        <target state="needs-review-translation">EMPTY</target>
+      </trans-unit>
+      <trans-unit id="4" translate="no">
+        <source>Brand</source>
+      </trans-unit>
+      <trans-unit id="5">
+        <source><x id="1"/></source>
+        <alt-trans><source>other</source><target>другой</target></alt-trans>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`,
+		},
+		{
+			name: "xliff20",
+			source: `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en">
+ <file id="f1">
+  <unit id="1">
+   <notes><note>Menu item</note></notes>
+   <segment state="initial">
+    <source>Open <pc id="1">file</pc><ph id="2"/></source>
+   </segment>
+   <ignorable><source> </source></ignorable>
+   <segment>
+    <source>Save</source>
+    <target>Сохранить</target>
+   </segment>
+  </unit>
+ </file>
+</xliff>`,
+			expected: `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="ru">
+ <file id="f1">
+  <unit id="1">
+   <notes><note>Menu item</note></notes>
+   <segment state="translated">
+    <source>Open <pc id="1">file</pc><ph id="2"/></source>
+    <target>OPEN <pc id="1">FILE</pc><ph id="2"/></target>
+   </segment>
+   <ignorable><source> </source></ignorable>
+   <segment>
+    <source>Save</source>
+    <target>Сохранить</target>
+   </segment>
+  </unit>
+ </file>
+</xliff>`,
+		},
+		{name: "not_xliff", source: `<root/>`, err: "failed to parse xliff: xliff element not found"},
+		{name: "invalid", source: `<xliff><file>`, err: `failed to parse xliff: not closed element "file"`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Translate(context.Background(), []byte(tc.source), "ru", testfn.UpperText)
+			if err != nil {
+				if e := err.Error(); e != tc.err {
+					t.Errorf("expected error %q, got %q", tc.err, e)
+				}
+				return
+			}
+
+			if tc.err != "" {
+				t.Fatalf("expected error %q", tc.err)
+			}
+
+			if s := string(result); s != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, s)
+			}
+		})
+	}
+}
+
+func TestTranslateLost(t *testing.T) {
+	const source = `<xliff version="1.2"><file target-language="ru"><body>` +
+		`<trans-unit id="1"><source>a <x id="1"/> b</source></trans-unit></body></file></xliff>`
+
+	lost := func(_ context.Context, texts []string) ([]string, error) {
+		return []string{"потеряно"}, nil
+	}
+
+	_, err := Translate(context.Background(), []byte(source), "ru", lost)
+	if err == nil || !strings.Contains(err.Error(), `lost protected element "<x id="1"/>"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSetAttr(t *testing.T) {
+	testCases := []struct {
+		tag      string
+		expected string
+	}{
+		{tag: "<target>", expected: `<target state="needs-review-translation">`},
+		{tag: "<target/>", expected: `<target state="needs-review-translation"/>`},
+		{tag: `<target xml:lang="ru" state='new'>`, expected: `<target xml:lang="ru" state="needs-review-translation">`},
+		{tag: `<target note=" state='x'" state = "new" >`, expected: `<target note=" state='x'" state = "needs-review-translation" >`},
+		{tag: `<target substate="x">`, expected: `<target substate="x" state="needs-review-translation">`},
+	}
+
+	for i, tc := range testCases {
+		if tag := setAttr(tc.tag, "state", StateReview); tag != tc.expected {
+			t.Errorf("case %d: expected %q, got %q", i, tc.expected, tag)
+		}
+	}
+}